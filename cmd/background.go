@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/cmd/server"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/geoip"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/mailer"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/realtime"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/scheduler"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newEmailService loads the email templates and creates the configured mailer
+func newEmailService(cfg *config.Config, db *gorm.DB, logger *zap.Logger) (*s.EmailService, error) {
+	renderer, err := email.NewRenderer(cfg.App.Name, cfg.Email.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email templates: %w", err)
+	}
+	emailMailer, err := mailer.New(cfg.Email, cfg.SMTP, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mailer: %w", err)
+	}
+	return s.NewEmailService(db, emailMailer, cfg.Email.From, renderer), nil
+}
+
+// newRealtimeHub creates the hub publishing events to users' open streams
+func newRealtimeHub(cfg *config.Config, redisService *s.RedisService, logger *zap.Logger) *realtime.Hub {
+	return realtime.NewHub(redisService.Client(), realtime.Config{
+		HeartbeatInterval: cfg.Realtime.HeartbeatInterval,
+		MaxConnections:    cfg.Realtime.MaxConnections,
+		HistorySize:       int64(cfg.Realtime.HistorySize),
+		HistoryTTL:        cfg.Realtime.HistoryTTL,
+	}, logger)
+}
+
+// newLoginAlertService creates the service alerting users about sign-ins from
+// new devices, or returns nil if alerts are disabled. The returned locator
+// should be closed on shutdown.
+func newLoginAlertService(cfg *config.Config, db *gorm.DB, redisService *s.RedisService, emailService *s.EmailService, notificationService *s.NotificationService, bus *events.Bus, logger *zap.Logger) (*s.LoginAlertService, *geoip.Locator, error) {
+	if !cfg.LoginAlerts.Enabled {
+		return nil, nil, nil
+	}
+	var locator *geoip.Locator
+	if cfg.LoginAlerts.GeoIPDatabase != "" {
+		var err error
+		if locator, err = geoip.Open(cfg.LoginAlerts.GeoIPDatabase); err != nil {
+			return nil, nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+		}
+	} else {
+		logger.Info("No GeoIP database configured, sign-ins will not be located")
+	}
+	return s.NewLoginAlertService(db, redisService, emailService, notificationService, bus, locator, cfg.LoginAlerts), locator, nil
+}
+
+// newEventBus creates the event bus with its subscribers
+func newEventBus(db *gorm.DB, webhookService *s.WebhookService, notificationService *s.NotificationService, hub *realtime.Hub, logger *zap.Logger) *events.Bus {
+	bus := events.NewBus(db, logger)
+	webhookService.Subscribe(bus)
+	notificationService.Subscribe(bus)
+	hub.Subscribe(bus)
+	return bus
+}
+
+// background selects the workers startBackground runs besides the email outbox
+type background struct {
+	jobs      bool
+	scheduler bool
+}
+
+// startBackground starts the workers that run outside the request path and
+// returns their shutdown hooks, to be run before Redis and the database close
+func startBackground(cfg *config.Config, run background, db *gorm.DB, redisService *s.RedisService, bus *events.Bus, emailService *s.EmailService, jwtService *s.JWTService, userService *s.UserService, webhookService *s.WebhookService, logger *zap.Logger) ([]server.ShutdownHook, error) {
+	// deliver queued emails
+	emailWorker := s.NewEmailOutboxWorker(db, emailService, cfg.Email, logger)
+	// publish events left in the outbox, then wait for async subscribers
+	hooks := []server.ShutdownHook{
+		{Name: "email outbox", Fn: emailWorker.Stop},
+		{Name: "events", Fn: bus.Stop},
+	}
+
+	// run scheduled maintenance, on one replica at a time
+	var sched *scheduler.Scheduler
+	if run.scheduler {
+		history := scheduler.NewHistory(db)
+		sched = scheduler.New(redisService.Client(), history, logger)
+		if err := s.RegisterScheduledJobs(sched, cfg.Scheduler, userService, emailService, jwtService, history, logger); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, server.ShutdownHook{Name: "scheduler", Fn: sched.Stop})
+	}
+
+	// run background jobs
+	var jobWorker *jobs.Worker
+	if run.jobs {
+		jobWorker = jobs.NewWorker(redisService.Client(), jobs.WorkerConfig{
+			Queues:            cfg.Jobs.Queues,
+			Concurrency:       cfg.Jobs.Concurrency,
+			PollInterval:      cfg.Jobs.PollInterval,
+			VisibilityTimeout: cfg.Jobs.VisibilityTimeout,
+			RetryBaseDelay:    cfg.Jobs.RetryBaseDelay,
+			RetryMaxDelay:     cfg.Jobs.RetryMaxDelay,
+		}, logger)
+		s.RegisterJobHandlers(jobWorker, emailService, jwtService, webhookService, logger)
+		hooks = append(hooks, server.ShutdownHook{Name: "jobs", Fn: jobWorker.Stop})
+	}
+
+	// start only once everything is set up, so a failure leaves nothing running
+	emailWorker.Start()
+	bus.Start(cfg.Events.RelayInterval)
+	if sched != nil {
+		sched.Start()
+	}
+	if jobWorker != nil {
+		jobWorker.Start()
+	}
+	return hooks, nil
+}
+
+// closeStores returns the shutdown hooks closing Redis and the database
+func closeStores(db *gorm.DB, redisService *s.RedisService) []server.ShutdownHook {
+	return []server.ShutdownHook{
+		{Name: "redis", Fn: func(ctx context.Context) error {
+			return redisService.Close()
+		}},
+		{Name: "database", Fn: func(ctx context.Context) error {
+			return database.Close(db)
+		}},
+	}
+}
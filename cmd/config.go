@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"go.uber.org/zap"
+)
+
+const configUsage = `usage: config check [-connect]
+
+Validates the environment without starting any listeners.`
+
+// runConfig implements the `config` subcommand
+func runConfig(args []string, logger *zap.Logger) error {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return errors.New("missing or unknown config command")
+	}
+
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	connect := fs.Bool("connect", false, "also connect to the database and Redis")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	fields := []zap.Field{
+		zap.String("environment", cfg.App.Environment),
+		zap.String("server", fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)),
+		zap.String("redis", fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)),
+		zap.String("email_transport", cfg.Email.Transport),
+	}
+	if cfg.Email.Transport == "smtp" {
+		fields = append(fields, zap.String("smtp", fmt.Sprintf("%s:%d", cfg.SMTP.Host, cfg.SMTP.Port)))
+	}
+	logger.Info("Configuration is valid", fields...)
+	if !*connect {
+		return nil
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	defer database.Close(db)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	logger.Info("Database connection OK")
+
+	redisService, err := s.NewRedisService(context.Background(), cfg.Redis)
+	if err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	defer redisService.Close()
+	logger.Info("Redis connection OK")
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	r "github.com/md-asharaf/go-fiber-boilerplate/internal/api/routes"
+	"go.uber.org/zap"
+)
+
+const docsUsage = `usage: docs <command>
+
+commands:
+  generate  write the OpenAPI document to -out ("-" for stdout)
+  check     fail if any /api/v1 route is missing from the document`
+
+// runDocs implements the `docs` subcommand. The routes are registered
+// without services, so no configuration or database is needed.
+func runDocs(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("docs", flag.ContinueOnError)
+	out := fs.String("out", "docs/openapi.json", "file the document is written to")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, docsUsage) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing docs command")
+	}
+
+	command := fs.Arg(0)
+	if command != "generate" && command != "check" {
+		fs.Usage()
+		return fmt.Errorf("unknown docs command: %s", command)
+	}
+
+	document, err := r.SetupRoutes(fiber.New(), &r.Services{}, r.Options{Logger: zap.NewNop()})
+	if err != nil {
+		return err
+	}
+	if command == "check" {
+		logger.Info("Every route is documented", zap.Int("paths", len(document.Paths)))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	logger.Info("Wrote OpenAPI document", zap.String("path", *out))
+	return nil
+}
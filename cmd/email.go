@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"go.uber.org/zap"
+)
+
+const emailUsage = `usage: email <command>
+
+commands:
+  list                 list the email templates
+  preview <template>   render a template with sample data (-locale, -format html|text|subject, -out)`
+
+// runEmail implements the `email` subcommand. It only renders templates, so
+// no configuration or SMTP server is needed.
+func runEmail(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("email", flag.ContinueOnError)
+	locale := fs.String("locale", i18n.DefaultLocale, "locale to render ("+strings.Join(i18n.Supported(), ", ")+")")
+	format := fs.String("format", "html", "part to print: html, text or subject")
+	dir := fs.String("dir", os.Getenv("EMAIL_TEMPLATES_DIR"), "directory of template overrides")
+	appName := fs.String("app-name", envOr("APP_NAME", "go-fiber-boilerplate"), "application name shown in emails")
+	out := fs.String("out", "", "file to write instead of stdout")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, emailUsage); fs.PrintDefaults() }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing email command")
+	}
+
+	switch command, rest := fs.Arg(0), fs.Args()[1:]; command {
+	case "list":
+		for _, name := range email.Templates() {
+			fmt.Println(name)
+		}
+		return nil
+	case "preview":
+		if len(rest) != 1 {
+			return errors.New("usage: email preview <template>")
+		}
+		if !i18n.IsSupported(*locale) {
+			return fmt.Errorf("unsupported locale: %s", *locale)
+		}
+		renderer, err := email.NewRenderer(*appName, *dir)
+		if err != nil {
+			return err
+		}
+		data, err := email.SampleData(rest[0])
+		if err != nil {
+			return err
+		}
+		rendered, err := renderer.Render(*locale, rest[0], data)
+		if err != nil {
+			return err
+		}
+
+		var output string
+		switch *format {
+		case "html":
+			output = rendered.HTML
+		case "text":
+			output = rendered.Text
+		case "subject":
+			output = rendered.Subject + "\n"
+		default:
+			return fmt.Errorf("unknown format: %s", *format)
+		}
+		if *out == "" {
+			fmt.Print(output)
+			return nil
+		}
+		if err := os.WriteFile(*out, []byte(output), 0o644); err != nil {
+			return err
+		}
+		logger.Info("Wrote email preview", zap.String("path", *out))
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown email command: %s", command)
+	}
+}
+
+// envOr returns the environment variable key, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
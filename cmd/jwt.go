@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"go.uber.org/zap"
+)
+
+const jwtUsage = `usage: jwt <command> [flags]
+
+commands:
+  rotate  create a new signing key and retire the current one after -grace`
+
+// runJWT implements the `jwt` subcommand
+func runJWT(args []string, logger *zap.Logger) error {
+	if len(args) == 0 || args[0] != "rotate" {
+		fmt.Fprintln(os.Stderr, jwtUsage)
+		return errors.New("missing or unknown jwt command")
+	}
+
+	fs := flag.NewFlagSet("jwt rotate", flag.ContinueOnError)
+	grace := fs.Duration("grace", 7*24*time.Hour, "how long tokens signed by the previous key stay valid")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, db, err := loadDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	key, err := s.NewJWTService(db, cfg.JWT.Secret, cfg.JWT.SecretGrace).RotateKey(context.Background(), *grace)
+	if err != nil {
+		return err
+	}
+	logger.Info("Rotated JWT signing key", zap.String("kid", key.ID), zap.Duration("grace", *grace))
+	return nil
+}
@@ -1,67 +1,83 @@
 package main
 
 import (
-	"github.com/gofiber/fiber/v2"
-	"github.com/md-asharaf/go-fiber-boilerplate/cmd/server"
-	r "github.com/md-asharaf/go-fiber-boilerplate/internal/api/routes"
+	"fmt"
+	"os"
+
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
-	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
-	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
 	u "github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+const usage = `usage: go-fiber-boilerplate <command> [arguments]
+
+commands:
+  serve                 run the HTTP server (default)
+  worker                run the background workers and scheduler without the server
+  migrate               manage the database schema
+  seed                  load fixture data
+  user create           create a user (-admin for an admin account)
+  user reset-password   reset a user's password
+  jwt rotate            rotate the JWT signing key
+  config check          validate the environment without starting listeners
+  docs generate         write the OpenAPI document
+  docs check            fail if a route is missing from the OpenAPI document
+  email preview         render an email template with sample data
+
+Run "<command> -h" for the flags of a command.`
+
 func main() {
 	//init logger
 	logger := u.InitLogger()
-	//Load conf
-	config, err := config.Load()
-	if err != nil {
-		logger.Fatal("Failed to load configuration", zap.Error(err))
+	defer logger.Sync()
+
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = runServe(args, logger)
+	case "worker":
+		err = runWorker(args, logger)
+	case "migrate":
+		err = runMigrate(args, logger)
+	case "seed":
+		err = runSeed(args, logger)
+	case "user":
+		err = runUser(args, logger)
+	case "jwt":
+		err = runJWT(args, logger)
+	case "config":
+		err = runConfig(args, logger)
+	case "docs":
+		err = runDocs(args, logger)
+	case "email":
+		err = runEmail(args, logger)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		logger.Fatal("Unknown command", zap.String("command", command))
 	}
-	// init db
-	db, err := database.Connect(config.Database)
 	if err != nil {
-		logger.Fatal("Failed to connect to database", zap.Error(err))
+		logger.Fatal("Command failed", zap.String("command", command), zap.Error(err))
 	}
-	// run migrations
-	if err := db.AutoMigrate(&m.User{}); err != nil {
-		logger.Fatal("Failed to run database migrations", zap.Error(err))
+}
+
+// loadDatabase loads the configuration and connects to the database for CLI commands
+func loadDatabase() (*config.Config, *gorm.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
-	// init redis
-	redisService, err := s.NewRedisService(config.Redis)
+	db, err := database.Connect(cfg.Database)
 	if err != nil {
-		logger.Fatal("Failed to initialize Redis service", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	// init email,jwt,otp services
-	emailService := s.NewEmailService(config.SMTP)
-	jwtService := s.NewJWTService(config.JWT.Secret)
-	otpService := s.NewOtpService(emailService)
-
-	userService := s.NewUserService(db)
-	authService := s.NewAuthService(db, jwtService, redisService, emailService, otpService)
-	// create fiber app
-	app := fiber.New()
-	// set up routes
-	r.SetupRoutes(app, &r.Services{
-		AuthService:  authService,
-		UserService:  userService,
-		JWTService:   jwtService,
-		RedisService: redisService,
-		EmailService: emailService,
-		OtpService:   otpService,
-	})
-	// start server
-	server.StartServer(app, config.Server, logger)
-	// graceful shutdown and close redis and db
-	defer func() {
-		logger.Info("Shutting down server")
-		if err := database.Close(db); err != nil {
-			logger.Error("Failed to close database connection", zap.Error(err))
-		}
-		if err := redisService.Close(); err != nil {
-			logger.Error("Failed to close Redis connection", zap.Error(err))
-		}
-	}()
+	return cfg, db, nil
 }
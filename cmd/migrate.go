@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	"github.com/md-asharaf/go-fiber-boilerplate/migrations"
+	"go.uber.org/zap"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [n]       roll back the last n migrations (default 1)
+  status         list migrations and whether they are applied
+  create <name>  create a new up/down migration pair in -dir`
+
+// runMigrate implements the `migrate` subcommand
+func runMigrate(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := fs.String("dir", "migrations", "directory new migrations are created in")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, migrateUsage) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing migrate command")
+	}
+
+	command, rest := fs.Arg(0), fs.Args()[1:]
+	if command == "create" {
+		if len(rest) != 1 {
+			return errors.New("usage: migrate create <name>")
+		}
+		upPath, downPath, err := database.CreateMigration(*dir, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println("Created", upPath)
+		fmt.Println("Created", downPath)
+		return nil
+	}
+
+	_, db, err := loadDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			logger.Info("Applied migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			logger.Info("No pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(rest) > 0 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", rest[0])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, migration := range rolledBack {
+			logger.Info("Rolled back migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown migrate command: %s", command)
+	}
+	return nil
+}
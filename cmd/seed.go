@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"go.uber.org/zap"
+)
+
+// runSeed implements the `seed` subcommand
+func runSeed(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "", "fixtures JSON file (defaults to the embedded fixtures)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fixtures, err := database.LoadFixtures(*file)
+	if err != nil {
+		return err
+	}
+	_, db, err := loadDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	// Fixtures raise no events
+	userService := s.NewUserService(db, nil)
+	for _, fixture := range fixtures.Users {
+		role := fixture.Role
+		if role == "" {
+			role = m.RoleUser
+		}
+		user, err := userService.CreateUser(context.Background(), m.RegisterInput{
+			Email:     fixture.Email,
+			Username:  fixture.Username,
+			Password:  fixture.Password,
+			FirstName: fixture.FirstName,
+			LastName:  fixture.LastName,
+		}, role)
+		if err != nil {
+			logger.Warn("Skipping fixture user", zap.String("email", fixture.Email), zap.Error(err))
+			continue
+		}
+		logger.Info("Seeded user", zap.String("email", user.Email), zap.String("role", user.Role))
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/md-asharaf/go-fiber-boilerplate/cmd/server"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/api/middleware"
+	r "github.com/md-asharaf/go-fiber-boilerplate/internal/api/routes"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/database"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/mailer"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/scheduler"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/sms"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/tracing"
+	"github.com/md-asharaf/go-fiber-boilerplate/migrations"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// runServe implements the `serve` subcommand
+func runServe(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load conf and init db
+	config, db, err := loadDatabase()
+	if err != nil {
+		return err
+	}
+	// run pending migrations
+	if config.Database.AutoMigrate {
+		migrator, err := database.NewMigrator(db, migrations.FS)
+		if err != nil {
+			return fmt.Errorf("failed to load database migrations: %w", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to run database migrations: %w", err)
+		}
+		logger.Info("Database migrations complete", zap.Int("applied", len(applied)))
+	}
+	// init redis
+	redisService, err := s.NewRedisService(context.Background(), config.Redis)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Redis service: %w", err)
+	}
+	// init email,jwt,otp services
+	emailService, err := newEmailService(config, db, logger)
+	if err != nil {
+		return err
+	}
+	jwtService := s.NewJWTService(db, config.JWT.Secret, config.JWT.SecretGrace)
+	otpChannels := []s.OTPChannel{
+		s.NewEmailOTPChannel(emailService),
+		s.NewConsoleOTPChannel(logger),
+	}
+	if slices.Contains(config.OTP.Channels, s.OTPChannelSMS) {
+		smsSender, err := sms.New(config.SMS, logger)
+		if err != nil {
+			return fmt.Errorf("failed to create SMS sender: %w", err)
+		}
+		otpChannels = append(otpChannels, s.NewSMSOTPChannel(smsSender, config.App.Name))
+	}
+	otpService := s.NewOtpService(redisService, config.OTP, otpChannels...)
+
+	jobClient := jobs.NewClient(redisService.Client(), config.Jobs.MaxAttempts)
+	webhookService := s.NewWebhookService(db, jobClient, config.Webhooks, config.App.Name)
+
+	hub := newRealtimeHub(config, redisService, logger)
+	notificationService := s.NewNotificationService(db, emailService, hub)
+	bus := newEventBus(db, webhookService, notificationService, hub, logger)
+
+	loginAlertService, locator, err := newLoginAlertService(config, db, redisService, emailService, notificationService, bus, logger)
+	if err != nil {
+		return err
+	}
+
+	userService := s.NewUserService(db, bus)
+	authService := s.NewAuthService(db, jwtService, redisService, emailService, otpService, bus, loginAlertService)
+	// readiness checks
+	healthService := s.NewHealthService(config.Health.Timeout, config.Health.CacheTTL)
+	healthService.Register("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthService.Register("redis", redisService.Ping)
+	if config.Health.CheckSMTP && config.Email.Transport == mailer.TransportSMTP {
+		healthService.Register("smtp", emailService.Ping)
+	}
+	// create fiber app
+	app := fiber.New(fiber.Config{
+		IdleTimeout:  config.Server.IdleTimeout,
+		ErrorHandler: middleware.ErrorHandler(config.App.ErrorFormat == "problem"),
+	})
+	srv := server.New(app, config.Server, logger)
+	// export traces over OTLP
+	tracing.InstallPropagator()
+	var shutdownTracing func(context.Context) error
+	if config.Tracing.Enabled {
+		if shutdownTracing, err = setupTracing(config.Tracing, db, redisService); err != nil {
+			return err
+		}
+	}
+	// expose metrics on the main app or a separate admin port
+	if config.Metrics.Enabled {
+		if err := setupMetrics(app, srv, config.Metrics, db, redisService, logger); err != nil {
+			return err
+		}
+	}
+	// set up routes
+	if _, err := r.SetupRoutes(app, &r.Services{
+		AuthService:         authService,
+		UserService:         userService,
+		JWTService:          jwtService,
+		RedisService:        redisService,
+		EmailService:        emailService,
+		OtpService:          otpService,
+		HealthService:       healthService,
+		JobClient:           jobClient,
+		RunHistory:          scheduler.NewHistory(db),
+		WebhookService:      webhookService,
+		RealtimeHub:         hub,
+		NotificationService: notificationService,
+	}, r.Options{
+		Ready:             srv.Ready,
+		Logger:            logger,
+		RequestTimeout:    config.Server.RequestTimeout,
+		ValidateRequests:  config.App.ValidateRequests,
+		ValidateResponses: config.App.ValidateResponses,
+	}); err != nil {
+		return err
+	}
+	// run the background workers and close redis and db once in-flight
+	// requests have drained
+	hooks, err := startBackground(config, background{
+		jobs:      config.Jobs.WorkerEnabled,
+		scheduler: config.Scheduler.Enabled,
+	}, db, redisService, bus, emailService, jwtService, userService, webhookService, logger)
+	if err != nil {
+		return err
+	}
+	// flush pending spans once the workers have stopped making them
+	if shutdownTracing != nil {
+		hooks = append(hooks, server.ShutdownHook{Name: "tracing", Fn: shutdownTracing})
+	}
+	for _, hook := range append(hooks, closeStores(db, redisService)...) {
+		srv.OnShutdown(hook.Name, hook.Fn)
+	}
+	srv.OnShutdown("geoip", func(context.Context) error {
+		return locator.Close()
+	})
+	// deliver events to open streams, ending them before the server stops
+	hub.Start()
+	srv.BeforeShutdown("realtime", hub.Stop)
+	// start server and block until shutdown
+	return srv.Run()
+}
+
+// setupTracing installs the OTLP tracer provider, instruments GORM and Redis
+// and returns the function flushing pending spans
+func setupTracing(cfg config.TracingConfig, db *gorm.DB, redisService *s.RedisService) (func(context.Context) error, error) {
+	exporter, err := tracing.NewExporter(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	shutdown, err := tracing.Setup(cfg, exporter)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register GORM tracing plugin: %w", err)
+	}
+	redisService.AddHook(tracing.RedisHook{})
+	return shutdown, nil
+}
+
+// setupMetrics instruments GORM and Redis and serves /metrics
+func setupMetrics(app *fiber.App, srv *server.Server, cfg config.MetricsConfig, db *gorm.DB, redisService *s.RedisService, logger *zap.Logger) error {
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return fmt.Errorf("failed to register GORM metrics plugin: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := metrics.RegisterDBStats(sqlDB, "postgres"); err != nil {
+		return fmt.Errorf("failed to register database pool metrics: %w", err)
+	}
+	redisService.AddHook(metrics.RedisHook{})
+
+	if cfg.Port == 0 {
+		app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	adminServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info("Starting metrics server", zap.String("address", adminServer.Addr))
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+	srv.OnShutdown("metrics", adminServer.Shutdown)
+	return nil
+}
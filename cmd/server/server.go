@@ -1,18 +1,123 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
 	"go.uber.org/zap"
 )
 
-func StartServer(app *fiber.App, cfg config.ServerConfig, logger *zap.Logger) {
-	serverAddr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	logger.Info("Starting server", zap.String("address", serverAddr))
+// ShutdownHook releases a resource once the HTTP server has stopped
+type ShutdownHook struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// Server runs the Fiber app and shuts it down gracefully on SIGINT/SIGTERM
+type Server struct {
+	app    *fiber.App
+	cfg    config.ServerConfig
+	logger *zap.Logger
+	ready  atomic.Bool
+	hooks  []ShutdownHook
+	// closers end long-lived connections before the server stops
+	closers []ShutdownHook
+}
+
+// New creates a server for app. Readiness turns on once the listener is up.
+func New(app *fiber.App, cfg config.ServerConfig, logger *zap.Logger) *Server {
+	s := &Server{
+		app:    app,
+		cfg:    cfg,
+		logger: logger,
+	}
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		s.ready.Store(true)
+		return nil
+	})
+	return s
+}
+
+// Ready reports whether the server should receive traffic
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// OnShutdown registers fn to run after the server stops accepting requests.
+// Hooks run in registration order, so register workers before the stores they use.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, ShutdownHook{Name: name, Fn: fn})
+}
+
+// BeforeShutdown registers fn to run once traffic has drained, right before
+// the server stops. The server waits for open connections, so use it to end
+// long-lived ones such as event streams.
+func (s *Server) BeforeShutdown(name string, fn func(ctx context.Context) error) {
+	s.closers = append(s.closers, ShutdownHook{Name: name, Fn: fn})
+}
+
+// Run listens until a termination signal arrives, then drains and shuts down
+func (s *Server) Run() error {
+	serverAddr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	s.logger.Info("Starting server", zap.String("address", serverAddr))
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- s.app.Listen(serverAddr)
+	}()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-listenErr:
+		s.ready.Store(false)
+		s.runHooks(s.hooks)
+		return fmt.Errorf("failed to start server: %w", err)
+	case sig := <-signals:
+		s.logger.Info("Shutting down server", zap.String("signal", sig.String()))
+	}
+
+	// Fail readiness first and give the load balancer time to notice
+	s.ready.Store(false)
+	if s.cfg.DrainDelay > 0 {
+		s.logger.Info("Draining traffic", zap.Duration("delay", s.cfg.DrainDelay))
+		select {
+		case <-time.After(s.cfg.DrainDelay):
+		case <-signals:
+			s.logger.Warn("Received second signal, skipping drain delay")
+		}
+	}
+
+	s.runHooks(s.closers)
+	var shutdownErr error
+	if err := s.app.ShutdownWithTimeout(s.cfg.ShutdownTimeout); err != nil {
+		s.logger.Error("Failed to shut down server cleanly", zap.Error(err))
+		shutdownErr = err
+	}
+	s.runHooks(s.hooks)
+	s.logger.Info("Server stopped")
+	return shutdownErr
+}
+
+// runHooks runs hooks in order, sharing one timeout
+func (s *Server) runHooks(hooks []ShutdownHook) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
 
-	if err := app.Listen(serverAddr); err != nil {
-		logger.Fatal("Failed to start server", zap.Error(err))
+	for _, hook := range hooks {
+		if err := hook.Fn(ctx); err != nil {
+			s.logger.Error("Shutdown hook failed", zap.String("hook", hook.Name), zap.Error(err))
+			continue
+		}
+		s.logger.Debug("Shutdown hook complete", zap.String("hook", hook.Name))
 	}
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"go.uber.org/zap"
+)
+
+// runWorker implements the `worker` subcommand: the email outbox, the job
+// worker and the scheduler without the HTTP server
+func runWorker(args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	noJobs := fs.Bool("no-jobs", false, "don't run the job worker")
+	noScheduler := fs.Bool("no-scheduler", false, "don't run the scheduler")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config, db, err := loadDatabase()
+	if err != nil {
+		return err
+	}
+	redisService, err := s.NewRedisService(context.Background(), config.Redis)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Redis service: %w", err)
+	}
+	emailService, err := newEmailService(config, db, logger)
+	if err != nil {
+		return err
+	}
+	jwtService := s.NewJWTService(db, config.JWT.Secret, config.JWT.SecretGrace)
+	jobClient := jobs.NewClient(redisService.Client(), config.Jobs.MaxAttempts)
+	webhookService := s.NewWebhookService(db, jobClient, config.Webhooks, config.App.Name)
+	// The worker only purges users, which raises no events
+	userService := s.NewUserService(db, nil)
+	hub := newRealtimeHub(config, redisService, logger)
+	notificationService := s.NewNotificationService(db, emailService, hub)
+
+	hooks, err := startBackground(config, background{
+		jobs:      !*noJobs,
+		scheduler: !*noScheduler,
+	}, db, redisService, newEventBus(db, webhookService, notificationService, hub, logger), emailService, jwtService, userService, webhookService, logger)
+	if err != nil {
+		return err
+	}
+	logger.Info("Worker started")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down worker")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
+	defer cancel()
+	for _, hook := range append(hooks, closeStores(db, redisService)...) {
+		if err := hook.Fn(shutdownCtx); err != nil {
+			logger.Error("Shutdown hook failed", zap.String("hook", hook.Name), zap.Error(err))
+		}
+	}
+	logger.Info("Worker stopped")
+	return nil
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/scheduler"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	u "github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+)
+
+// Page size limits of the admin list endpoints
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// EmailOutboxHandler lets admins inspect and retry queued emails
+type EmailOutboxHandler struct {
+	emailService *s.EmailService
+}
+
+// NewEmailOutboxHandler creates a new email outbox handler
+func NewEmailOutboxHandler(emailService *s.EmailService) *EmailOutboxHandler {
+	return &EmailOutboxHandler{
+		emailService: emailService,
+	}
+}
+
+// List returns outbox emails, optionally filtered by status
+func (h *EmailOutboxHandler) List(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", m.EmailStatusPending, m.EmailStatusSent, m.EmailStatusDead:
+	default:
+		return apperrors.New(apperrors.CodeInvalidInput, "status must be one of pending, sent, dead")
+	}
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	emails, err := h.emailService.ListOutbox(c.UserContext(), status, limit, offset)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, emails, i18n.T(c.UserContext(), "message.emails_fetched", nil))
+}
+
+// Get returns one outbox email
+func (h *EmailOutboxHandler) Get(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	msg, err := h.emailService.GetOutboxEmail(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, msg, i18n.T(c.UserContext(), "message.email_fetched", nil))
+}
+
+// Retry schedules a failed email for another round of delivery attempts
+func (h *EmailOutboxHandler) Retry(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	msg, err := h.emailService.RetryOutboxEmail(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, msg, i18n.T(c.UserContext(), "message.email_retried", nil))
+}
+
+// pageParams reads the limit and offset query parameters
+func pageParams(c *fiber.Ctx) (int, int, error) {
+	limit := c.QueryInt("limit", defaultPageSize)
+	offset := c.QueryInt("offset", 0)
+	if limit < 1 || limit > maxPageSize || offset < 0 {
+		return 0, 0, apperrors.New(apperrors.CodeInvalidInput, "limit must be between 1 and 200 and offset must not be negative")
+	}
+	return limit, offset, nil
+}
+
+// idParam parses the :id route parameter as a UUID
+func idParam(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, apperrors.Wrap(apperrors.CodeInvalidInput, "id must be a UUID", err)
+	}
+	return id, nil
+}
+
+// JobsHandler lets admins inspect queues and retry failed jobs
+type JobsHandler struct {
+	jobClient *jobs.Client
+}
+
+// NewJobsHandler creates a new jobs handler
+func NewJobsHandler(jobClient *jobs.Client) *JobsHandler {
+	return &JobsHandler{
+		jobClient: jobClient,
+	}
+}
+
+// Queues returns the depth of every queue
+func (h *JobsHandler) Queues(c *fiber.Ctx) error {
+	stats, err := h.jobClient.Stats(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, stats, i18n.T(c.UserContext(), "message.job_queues_fetched", nil))
+}
+
+// Failed returns the dead jobs of a queue, most recent first
+func (h *JobsHandler) Failed(c *fiber.Ctx) error {
+	queue := c.Query("queue", jobs.DefaultQueue)
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	failed, err := h.jobClient.Failed(c.UserContext(), queue, limit, offset)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, failed, i18n.T(c.UserContext(), "message.jobs_fetched", nil))
+}
+
+// Retry moves a failed job back to its queue
+func (h *JobsHandler) Retry(c *fiber.Ctx) error {
+	job, err := h.jobClient.Retry(c.UserContext(), c.Params("id"))
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return apperrors.Wrap(apperrors.CodeNotFound, "Job not found", err)
+	case errors.Is(err, jobs.ErrJobNotFailed):
+		return apperrors.Wrap(apperrors.CodeConflict, "Job has not failed", err)
+	case errors.Is(err, jobs.ErrUniqueKeyTaken):
+		return apperrors.Wrap(apperrors.CodeConflict, "Another job with the same unique key is queued", err)
+	case err != nil:
+		return err
+	}
+	return u.WriteSuccessResponse(c, job, i18n.T(c.UserContext(), "message.job_retried", nil))
+}
+
+// SchedulerHandler lets admins inspect the run history of scheduled jobs
+type SchedulerHandler struct {
+	history *scheduler.History
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(history *scheduler.History) *SchedulerHandler {
+	return &SchedulerHandler{
+		history: history,
+	}
+}
+
+// Runs returns scheduled runs, newest first, optionally filtered by job and status
+func (h *SchedulerHandler) Runs(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", m.RunStatusSucceeded, m.RunStatusFailed:
+	default:
+		return apperrors.New(apperrors.CodeInvalidInput, "status must be one of succeeded, failed")
+	}
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	runs, err := h.history.List(c.UserContext(), c.Query("job"), status, limit, offset)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, runs, i18n.T(c.UserContext(), "message.scheduled_runs_fetched", nil))
+}
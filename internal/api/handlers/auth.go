@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
 	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
 	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
 	u "github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
@@ -25,11 +27,11 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	if err := u.ParseAndValidateInput(c, &input); err != nil {
 		return err
 	}
-	resp, err := h.authService.Register(input)
+	resp, err := h.authService.Register(c.UserContext(), input)
 	if err != nil {
-		return u.WriteErrorResponse(c, fiber.StatusBadRequest, err.Error())
+		return err
 	}
-	return u.WriteSuccessResponse(c, resp, "User registered successfully")
+	return u.WriteSuccessResponse(c, resp, i18n.T(c.UserContext(), "message.user_registered", nil))
 }
 
 // Login handles user login for Fiber
@@ -38,9 +40,84 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	if err := u.ParseAndValidateInput(c, &input); err != nil {
 		return err
 	}
-	resp, err := h.authService.Login(input)
+	resp, err := h.authService.Login(c.UserContext(), input, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, resp, i18n.T(c.UserContext(), "message.user_logged_in", nil))
+}
+
+// RequestOTP sends a one-time sign-in code
+func (h *AuthHandler) RequestOTP(c *fiber.Ctx) error {
+	var input m.OTPRequestInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	resp, err := h.authService.RequestOTP(c.UserContext(), input, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, resp, i18n.T(c.UserContext(), "message.otp_sent", nil))
+}
+
+// RequestPhoneVerification texts a code to the authenticated user's phone
+func (h *AuthHandler) RequestPhoneVerification(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	if err := h.authService.RequestPhoneVerification(c.UserContext(), user, clientInfo(c)); err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, nil, i18n.T(c.UserContext(), "message.phone_code_sent", nil))
+}
+
+// VerifyPhone confirms the authenticated user's phone with a texted code
+func (h *AuthHandler) VerifyPhone(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	var input m.PhoneVerifyInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	resp, err := h.authService.VerifyPhone(c.UserContext(), user, input.Code)
 	if err != nil {
-		return u.WriteErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+		return err
+	}
+	return u.WriteSuccessResponse(c, resp, i18n.T(c.UserContext(), "message.phone_verified", nil))
+}
+
+// VerifyOTP signs in with a one-time code
+func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
+	var input m.OTPVerifyInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	resp, err := h.authService.VerifyOTP(c.UserContext(), input, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, resp, i18n.T(c.UserContext(), "message.user_logged_in", nil))
+}
+
+// RevokeSessions signs out every session with the token of a new sign-in alert
+func (h *AuthHandler) RevokeSessions(c *fiber.Ctx) error {
+	var input m.RevokeSessionsInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	if err := h.authService.RevokeSessions(c.UserContext(), input.Token); err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, nil, i18n.T(c.UserContext(), "message.sessions_revoked", nil))
+}
+
+// clientInfo describes the client of the request
+func clientInfo(c *fiber.Ctx) m.ClientInfo {
+	return m.ClientInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
 	}
-	return u.WriteSuccessResponse(c, resp, "User logged in successfully")
 }
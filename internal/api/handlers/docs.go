@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+// DocsHandler serves the OpenAPI document and its Swagger UI page
+type DocsHandler struct {
+	document *openapi.Document
+	ui       []byte
+}
+
+// NewDocsHandler creates a new docs handler. The document is set once every
+// route has been registered.
+func NewDocsHandler(specURL string) *DocsHandler {
+	return &DocsHandler{
+		ui: openapi.UIPage(specURL),
+	}
+}
+
+// SetDocument sets the document served by OpenAPI
+func (h *DocsHandler) SetDocument(document *openapi.Document) {
+	h.document = document
+}
+
+// OpenAPI returns the OpenAPI document
+func (h *DocsHandler) OpenAPI(c *fiber.Ctx) error {
+	return c.JSON(h.document)
+}
+
+// UI returns the Swagger UI page
+func (h *DocsHandler) UI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Send(h.ui)
+}
@@ -2,10 +2,46 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
 )
 
+// HealthHandler handles health check requests
+type HealthHandler struct {
+	healthService *s.HealthService
+	ready         func() bool
+}
+
+// NewHealthHandler creates a new health handler. ready reports whether the
+// server is accepting traffic and turns false while shutting down.
+func NewHealthHandler(healthService *s.HealthService, ready func() bool) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+		ready:         ready,
+	}
+}
+
 // HealthCheck provides health check endpoint for Fiber
-func HealthCheck(c *fiber.Ctx) error {
+func (h *HealthHandler) HealthCheck(c *fiber.Ctx) error {
+	if h.ready != nil && !h.ready() {
+		return utils.WriteErrorResponse(c, fiber.StatusServiceUnavailable, "shutting down")
+	}
 	return utils.WriteSuccessResponse(c, nil, "ok")
 }
+
+// Live reports only that the process is up and serving requests
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	return utils.WriteSuccessResponse(c, nil, "ok")
+}
+
+// Ready reports whether the server and its dependencies can handle traffic
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	if h.ready != nil && !h.ready() {
+		return utils.WriteErrorResponse(c, fiber.StatusServiceUnavailable, "shutting down")
+	}
+	report := h.healthService.Check(c.Context())
+	if !report.Healthy() {
+		return utils.WriteErrorResponseWithDetails(c, fiber.StatusServiceUnavailable, "dependency check failed", report)
+	}
+	return utils.WriteSuccessResponse(c, report, "ready")
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	u "github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+)
+
+// NotificationHandler serves the authenticated user's notifications
+type NotificationHandler struct {
+	notificationService *s.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *s.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List returns a page of notifications, newest first
+func (h *NotificationHandler) List(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	opts := s.ListNotificationsOptions{
+		Limit:    c.QueryInt("limit", defaultNotificationPageSize),
+		Cursor:   c.Query("cursor"),
+		Unread:   c.QueryBool("unread"),
+		Category: c.Query("category"),
+	}
+	if opts.Limit < 1 || opts.Limit > maxNotificationPageSize {
+		return apperrors.New(apperrors.CodeInvalidInput, "limit must be between 1 and 100")
+	}
+	if opts.Category != "" && !slices.Contains(m.NotificationCategories, opts.Category) {
+		return apperrors.New(apperrors.CodeInvalidInput, "category must be one of security, account")
+	}
+	page, err := h.notificationService.List(c.UserContext(), user.ID, opts)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, page, i18n.T(c.UserContext(), "message.notifications_fetched", nil))
+}
+
+// MarkRead marks a notification read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	notification, err := h.notificationService.MarkRead(c.UserContext(), user.ID, id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, notification, i18n.T(c.UserContext(), "message.notification_read", nil))
+}
+
+// MarkAllRead marks every notification read
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	count, err := h.notificationService.MarkAllRead(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, m.NotificationsMarked{Count: count}, i18n.T(c.UserContext(), "message.notifications_read", nil))
+}
+
+// Delete deletes a notification
+func (h *NotificationHandler) Delete(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.notificationService.Delete(c.UserContext(), user.ID, id); err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, nil, i18n.T(c.UserContext(), "message.notification_deleted", nil))
+}
+
+// Preferences returns the delivery preferences of every category
+func (h *NotificationHandler) Preferences(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	prefs, err := h.notificationService.Preferences(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, prefs, i18n.T(c.UserContext(), "message.notification_preferences_fetched", nil))
+}
+
+// UpdatePreferences changes the delivery preferences of some categories
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return apperrors.ErrUnauthorized
+	}
+	var input m.NotificationPreferencesInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	prefs, err := h.notificationService.UpdatePreferences(c.UserContext(), user.ID, input)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, prefs, i18n.T(c.UserContext(), "message.notification_preferences_updated", nil))
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/realtime"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+)
+
+// sseRetry is how long EventSource clients wait before reconnecting
+const sseRetry = 3 * time.Second
+
+// RealtimeHandler streams the authenticated user's events
+type RealtimeHandler struct {
+	hub *realtime.Hub
+}
+
+// NewRealtimeHandler creates a new realtime handler
+func NewRealtimeHandler(hub *realtime.Hub) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub: hub,
+	}
+}
+
+// Events streams events as Server-Sent Events
+func (h *RealtimeHandler) Events(c *fiber.Ctx) error {
+	sub, err := h.connect(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Stop proxies such as nginx from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.hub.HeartbeatInterval()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+		for {
+			if err := w.Flush(); err != nil {
+				// The client went away
+				return
+			}
+			select {
+			case msg, ok := <-sub.Messages():
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+			case <-ticker.C:
+				w.WriteString(": ping\n\n")
+				_ = sub.Heartbeat(context.Background())
+			}
+		}
+	})
+	return nil
+}
+
+// WebSocket streams events as JSON messages over a WebSocket
+func (h *RealtimeHandler) WebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return apperrors.ErrUpgradeRequired
+	}
+	sub, err := h.connect(c)
+	if err != nil {
+		return err
+	}
+	err = websocket.New(func(conn *websocket.Conn) {
+		h.serveWebSocket(conn, sub)
+	})(c)
+	if err != nil {
+		sub.Close()
+	}
+	return err
+}
+
+// serveWebSocket writes sub's messages to conn until either side closes
+func (h *RealtimeHandler) serveWebSocket(conn *websocket.Conn, sub *realtime.Subscription) {
+	defer sub.Close()
+	heartbeat := h.hub.HeartbeatInterval()
+
+	// Clients don't send anything, but reading processes their pongs and
+	// notices when they go away
+	gone := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				if err := sub.Err(); err != nil {
+					// Reconnecting needs a new token
+					closing = websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error())
+				}
+				_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second))
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(heartbeat))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+				return
+			}
+			_ = sub.Heartbeat(context.Background())
+		}
+	}
+}
+
+// connect subscribes to the user's events, resuming after the Last-Event-ID
+// header or the last_event_id query parameter, until the token expires
+func (h *RealtimeHandler) connect(c *fiber.Ctx) (*realtime.Subscription, error) {
+	user, ok := c.Locals("user").(*m.User)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+	var expiresAt time.Time
+	if claims, ok := c.Locals("claims").(*s.Claims); ok && claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	sub, err := h.hub.Connect(c.UserContext(), user.ID, lastEventID, expiresAt)
+	switch {
+	case errors.Is(err, realtime.ErrTooManyConnections):
+		return nil, apperrors.New(apperrors.CodeTooManyRequests, "Too many open connections")
+	case errors.Is(err, realtime.ErrStopped):
+		return nil, apperrors.New(apperrors.CodeServiceUnavailable, "Server is shutting down")
+	}
+	return sub, err
+}
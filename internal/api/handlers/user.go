@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/services"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
@@ -21,13 +23,9 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 
 // Me returns the current authenticated user's info for Fiber
 func (h *UserHandler) Me(c *fiber.Ctx) error {
-	userValue := c.Locals("user")
-	if userValue == nil {
-		return utils.WriteErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized: missing user context")
-	}
-	user, ok := userValue.(*models.User)
+	user, ok := c.Locals("user").(*models.User)
 	if !ok {
-		return utils.WriteErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized: invalid user context")
+		return apperrors.ErrUnauthorized
 	}
-	return utils.WriteSuccessResponse(c, user.ToResponse(), "User fetched successfully")
+	return utils.WriteSuccessResponse(c, user.ToResponse(), i18n.T(c.UserContext(), "message.user_fetched", nil))
 }
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	u "github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+)
+
+// WebhookHandler lets admins manage webhook endpoints and their deliveries
+type WebhookHandler struct {
+	webhookService *s.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *s.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// List returns webhook endpoints
+func (h *WebhookHandler) List(c *fiber.Ctx) error {
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	endpoints, err := h.webhookService.ListEndpoints(c.UserContext(), limit, offset)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, endpoints, i18n.T(c.UserContext(), "message.webhooks_fetched", nil))
+}
+
+// Create registers an endpoint and returns its signing secret
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var input m.WebhookEndpointInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	endpoint, err := h.webhookService.CreateEndpoint(c.UserContext(), input)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, endpoint, i18n.T(c.UserContext(), "message.webhook_created", nil))
+}
+
+// Get returns one endpoint
+func (h *WebhookHandler) Get(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	endpoint, err := h.webhookService.GetEndpoint(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, endpoint, i18n.T(c.UserContext(), "message.webhook_fetched", nil))
+}
+
+// Update replaces an endpoint's settings
+func (h *WebhookHandler) Update(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	var input m.WebhookEndpointInput
+	if err := u.ParseAndValidateInput(c, &input); err != nil {
+		return err
+	}
+	endpoint, err := h.webhookService.UpdateEndpoint(c.UserContext(), id, input)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, endpoint, i18n.T(c.UserContext(), "message.webhook_updated", nil))
+}
+
+// Delete deletes an endpoint and its deliveries
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.webhookService.DeleteEndpoint(c.UserContext(), id); err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, nil, i18n.T(c.UserContext(), "message.webhook_deleted", nil))
+}
+
+// Test sends a test event to an endpoint and returns the delivery
+func (h *WebhookHandler) Test(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := h.webhookService.SendTest(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, delivery, i18n.T(c.UserContext(), "message.webhook_tested", nil))
+}
+
+// Deliveries returns an endpoint's deliveries, optionally filtered by status
+func (h *WebhookHandler) Deliveries(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	status := c.Query("status")
+	switch status {
+	case "", m.DeliveryStatusPending, m.DeliveryStatusSucceeded, m.DeliveryStatusFailed:
+	default:
+		return apperrors.New(apperrors.CodeInvalidInput, "status must be one of pending, succeeded, failed")
+	}
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+	deliveries, err := h.webhookService.ListDeliveries(c.UserContext(), id, status, limit, offset)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, deliveries, i18n.T(c.UserContext(), "message.deliveries_fetched", nil))
+}
+
+// Delivery returns one delivery with its attempts
+func (h *WebhookHandler) Delivery(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := h.webhookService.GetDelivery(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, delivery, i18n.T(c.UserContext(), "message.delivery_fetched", nil))
+}
+
+// Replay queues a failed delivery again
+func (h *WebhookHandler) Replay(c *fiber.Ctx) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := h.webhookService.ReplayDelivery(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return u.WriteSuccessResponse(c, delivery, i18n.T(c.UserContext(), "message.delivery_replayed", nil))
+}
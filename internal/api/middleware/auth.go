@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
 	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
-	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
 )
 
 // CORS middleware for Fiber
@@ -11,7 +16,8 @@ func CORS() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		c.Set("Access-Control-Allow-Origin", "*")
 		c.Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept-Language, X-Request-ID")
+		c.Set("Access-Control-Expose-Headers", "X-Request-ID, Content-Language")
 		if c.Method() == fiber.MethodOptions {
 			return c.SendStatus(fiber.StatusOK)
 		}
@@ -21,21 +27,74 @@ func CORS() fiber.Handler {
 
 // JWTAuth middleware for Fiber
 func JWTAuth(jwtService *s.JWTService, userService *s.UserService) fiber.Handler {
+	return jwtAuth(jwtService, userService, false)
+}
+
+// StreamAuth is JWTAuth for event streams. Browsers can't set headers on
+// EventSource and WebSocket requests, so the token may also be passed in the
+// access_token query parameter.
+func StreamAuth(jwtService *s.JWTService, userService *s.UserService) fiber.Handler {
+	return jwtAuth(jwtService, userService, true)
+}
+
+func jwtAuth(jwtService *s.JWTService, userService *s.UserService, allowQuery bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" || len(authHeader) < 7 || authHeader[:7] != "Bearer " {
-			return utils.WriteErrorResponse(c, fiber.StatusUnauthorized, "Missing or invalid Authorization header")
+		tokenString := bearerToken(c)
+		if tokenString == "" && allowQuery {
+			tokenString = c.Query("access_token")
 		}
-		tokenString := authHeader[7:]
-		claims, err := jwtService.ValidateToken(tokenString)
+		if tokenString == "" {
+			return apperrors.ErrUnauthorized
+		}
+		claims, err := jwtService.ValidateToken(c.UserContext(), tokenString)
 		if err != nil {
-			return utils.WriteErrorResponse(c, fiber.StatusUnauthorized, "Invalid JWT token")
+			return apperrors.Wrap(apperrors.CodeAuthInvalidToken, apperrors.ErrInvalidToken.Message, err)
+		}
+		user, err := userService.GetUserByID(c.UserContext(), claims.UserID)
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return apperrors.Wrap(apperrors.CodeAuthInvalidToken, "User not found for JWT claims", err)
 		}
-		user, err := userService.GetUserByID(claims.UserID)
 		if err != nil {
-			return utils.WriteErrorResponse(c, fiber.StatusUnauthorized, "User not found for JWT claims")
+			return err
+		}
+		// Signing out everywhere revokes the tokens issued until then. Token
+		// times have whole seconds, so a token issued in the second of the
+		// revocation is revoked too, even if it was issued just after it.
+		if user.SessionsRevokedAt != nil && (claims.IssuedAt == nil || !claims.IssuedAt.After(user.SessionsRevokedAt.Truncate(time.Second))) {
+			return apperrors.New(apperrors.CodeAuthInvalidToken, "Token has been revoked")
 		}
 		c.Locals("user", user)
+		c.Locals("claims", claims)
+		// A saved language preference wins over Accept-Language
+		if i18n.IsSupported(user.Locale) {
+			setLocale(c, user.Locale)
+		}
 		return c.Next()
 	}
 }
+
+// bearerToken returns the token of a Bearer Authorization header, if any
+func bearerToken(c *fiber.Ctx) string {
+	authHeader := c.Get("Authorization")
+	if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
+		return ""
+	}
+	return authHeader[7:]
+}
+
+// RequireRole allows the request through only if the authenticated user has
+// one of roles. It must run after JWTAuth.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*models.User)
+		if !ok {
+			return apperrors.ErrUnauthorized
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				return c.Next()
+			}
+		}
+		return apperrors.ErrForbidden
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.uber.org/zap"
+)
+
+// ErrorHandler renders errors returned by handlers. Domain errors keep their
+// code and message, translated into the request locale; anything unknown
+// becomes a 500 carrying the request ID, and its cause is only logged
+// server-side. Responses use the ErrorResponse
+// envelope unless the client asks for application/problem+json or
+// problemJSON makes it the default.
+func ErrorHandler(problemJSON bool) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		appErr := toAppError(err)
+		requestID := ""
+		if appErr.Status() >= fiber.StatusInternalServerError {
+			requestID = c.GetRespHeader(HeaderRequestID)
+			utils.RequestLogger(c).Error("Request failed", zap.String("code", string(appErr.Code)), zap.Error(err))
+		}
+		appErr = localize(c, appErr)
+		if wantsProblemJSON(c, problemJSON) {
+			return utils.WriteProblemResponse(c, appErr, requestID)
+		}
+		return utils.WriteAppErrorResponse(c, appErr, requestID)
+	}
+}
+
+// localize translates the error's generic message into the request locale
+func localize(c *fiber.Ctx, appErr *apperrors.Error) *apperrors.Error {
+	message := i18n.TranslateError(c.UserContext(), string(appErr.Code), appErr.Message)
+	if message == appErr.Message {
+		return appErr
+	}
+	localized := *appErr
+	localized.Message = message
+	return &localized
+}
+
+// wantsProblemJSON negotiates the error format from the Accept header
+func wantsProblemJSON(c *fiber.Ctx, problemJSON bool) bool {
+	if problemJSON {
+		return c.Accepts(utils.MIMEApplicationProblemJSON, fiber.MIMEApplicationJSON) != fiber.MIMEApplicationJSON
+	}
+	return c.Accepts(fiber.MIMEApplicationJSON, utils.MIMEApplicationProblemJSON) == utils.MIMEApplicationProblemJSON
+}
+
+// toAppError converts any error into the domain error it should be rendered as
+func toAppError(err error) *apperrors.Error {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr):
+		return fiberErrorToAppError(fiberErr)
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperrors.ErrRequestTimeout
+	case errors.Is(err, context.Canceled):
+		return apperrors.ErrRequestCancelled
+	default:
+		return apperrors.Wrap(apperrors.CodeInternal, apperrors.ErrInternal.Message, err)
+	}
+}
+
+// fiberErrorToAppError maps Fiber's own errors, such as unmatched routes
+func fiberErrorToAppError(err *fiber.Error) *apperrors.Error {
+	switch err.Code {
+	case fiber.StatusNotFound:
+		return apperrors.New(apperrors.CodeNotFound, err.Message)
+	case fiber.StatusMethodNotAllowed:
+		return apperrors.New(apperrors.CodeMethodNotAllowed, err.Message)
+	case fiber.StatusRequestEntityTooLarge:
+		return apperrors.New(apperrors.CodePayloadTooLarge, err.Message)
+	case fiber.StatusUnsupportedMediaType:
+		return apperrors.ErrUnsupportedMediaType
+	case fiber.StatusUpgradeRequired:
+		return apperrors.ErrUpgradeRequired
+	case fiber.StatusServiceUnavailable:
+		return apperrors.New(apperrors.CodeServiceUnavailable, err.Message)
+	}
+	if err.Code < fiber.StatusInternalServerError {
+		return apperrors.New(apperrors.CodeInvalidInput, http.StatusText(err.Code))
+	}
+	return apperrors.Wrap(apperrors.CodeInternal, apperrors.ErrInternal.Message, err)
+}
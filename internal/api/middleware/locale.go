@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+)
+
+// Locale negotiates the response language from the Accept-Language header and
+// stores it in the request context for i18n.T
+func Locale() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		setLocale(c, i18n.Match(c.Get(fiber.HeaderAcceptLanguage)))
+		return c.Next()
+	}
+}
+
+// setLocale makes locale the language of the current request
+func setLocale(c *fiber.Ctx, locale string) {
+	c.SetUserContext(i18n.WithLocale(c.UserContext(), locale))
+	c.Set(fiber.HeaderContentLanguage, locale)
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/tracing"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HeaderRequestID is the header used to accept and return request IDs
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 128
+
+// RequestLogger assigns or propagates an X-Request-ID, stores a request-scoped
+// logger carrying the request and trace IDs in c.Locals("logger") and the user
+// context, and logs one structured line per request once it completes.
+// Request headers and bodies are only logged at debug level, with sensitive
+// values redacted.
+func RequestLogger(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := c.Get(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		} else {
+			requestID = fiberutils.CopyString(requestID)
+		}
+		c.Set(HeaderRequestID, requestID)
+
+		ctx := c.UserContext()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", requestID))
+		requestLogger := logger.With(append([]zap.Field{zap.String("request_id", requestID)}, tracing.LogFields(ctx)...)...)
+		c.Locals("logger", requestLogger)
+		c.SetUserContext(utils.ContextWithLogger(ctx, requestLogger))
+
+		if requestLogger.Core().Enabled(zapcore.DebugLevel) {
+			headers := map[string]string{}
+			c.Request().Header.VisitAll(func(key, value []byte) {
+				headers[string(key)] = string(value)
+			})
+			fields := []zap.Field{zap.Any("headers", utils.RedactHeaders(headers))}
+			if body := c.Body(); len(body) > 0 {
+				fields = append(fields, zap.String("body", utils.RedactJSON(body)))
+			}
+			requestLogger.Debug("Request received", fields...)
+		}
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		fields := []zap.Field{
+			zap.String("method", c.Method()),
+			zap.String("route", c.Route().Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("bytes", responseSize(c)),
+			zap.String("ip", c.IP()),
+		}
+		if user, ok := c.Locals("user").(*models.User); ok {
+			fields = append(fields, zap.String("user_id", user.ID.String()))
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			requestLogger.Error("Request completed", fields...)
+		case status >= fiber.StatusBadRequest:
+			requestLogger.Warn("Request completed", fields...)
+		default:
+			requestLogger.Info("Request completed", fields...)
+		}
+		return err
+	}
+}
+
+// validRequestID accepts short IDs made of printable ASCII so client values
+// can't inject control characters into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// responseSize returns the length of the response body, or -1 for streamed
+// bodies, which reading would consume
+func responseSize(c *fiber.Ctx) int {
+	if c.Response().IsBodyStream() {
+		return -1
+	}
+	return len(c.Response().Body())
+}
+
+// responseStatus returns the status the response will be sent with, accounting
+// for errors that the app's error handler has not rendered yet
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	return toAppError(err).Status()
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+)
+
+// Metrics records request count and latency labeled by route template and status
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := c.Route().Path
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			// No route matched, so don't create a label per unknown path
+			route = "unmatched"
+		}
+
+		// Method is backed by the reused request buffer, so copy it before it becomes a label
+		labels := []string{fiberutils.CopyString(c.Method()), route, strconv.Itoa(status)}
+		metrics.HTTPRequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.uber.org/zap"
+)
+
+// OpenAPIValidation rejects requests that don't match the OpenAPI document:
+// path and query parameters, the request content type and the JSON body.
+// Failures use the VALIDATION_FAILED format of struct tag validation. With
+// validateResponses, successful responses are checked as well, so a handler
+// drifting from the published contract turns into a 500; use it in
+// development and tests only. WebSocket upgrades are not checked.
+func OpenAPIValidation(validator *openapi.Validator, validateResponses bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		op, pathParams := validator.Find(c.Method(), c.Path())
+		if op == nil {
+			return c.Next()
+		}
+		if err := validateRequest(c, validator, op, pathParams); err != nil {
+			return err
+		}
+		if err := c.Next(); err != nil || !validateResponses {
+			return err
+		}
+		if c.Response().StatusCode() == fiber.StatusSwitchingProtocols {
+			return nil
+		}
+		return validateResponse(c, validator, op)
+	}
+}
+
+// validateRequest checks the parameters and body of the request
+func validateRequest(c *fiber.Ctx, validator *openapi.Validator, op *openapi.OperationObject, pathParams map[string]string) error {
+	query := c.Context().QueryArgs()
+	issues := validator.ValidateParameters(op, pathParams, func(name string) (string, bool) {
+		return string(query.Peek(name)), query.Has(name)
+	})
+
+	if op.RequestBody != nil {
+		body := c.Body()
+		contentType := string(c.Request().Header.ContentType())
+		switch {
+		case len(body) == 0:
+			if op.RequestBody.Required {
+				issues = append(issues, openapi.Issue{Rule: "required"})
+			}
+		case openapi.RequestMediaType(op, contentType) == nil:
+			return apperrors.ErrUnsupportedMediaType
+		case isJSON(contentType):
+			bodyIssues, err := validator.ValidateJSON(openapi.RequestMediaType(op, contentType).Schema, body)
+			if err != nil {
+				return apperrors.Wrap(apperrors.CodeInvalidInput, "Invalid input", err)
+			}
+			issues = append(issues, bodyIssues...)
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return apperrors.New(apperrors.CodeValidationFailed, "Validation failed").
+		WithDetails(issueFieldErrors(c.UserContext(), issues))
+}
+
+// validateResponse checks the status, content type and JSON body written by the handler
+func validateResponse(c *fiber.Ctx, validator *openapi.Validator, op *openapi.OperationObject) error {
+	status := c.Response().StatusCode()
+	contentType := string(c.Response().Header.ContentType())
+
+	var fieldErrors []utils.FieldError
+	media, documented := openapi.ResponseMediaType(op, status, contentType)
+	switch {
+	case !documented:
+		fieldErrors = append(fieldErrors, utils.FieldError{
+			Field:   "status",
+			Rule:    "documented",
+			Param:   strconv.Itoa(status),
+			Message: "status " + strconv.Itoa(status) + " is not documented for " + op.OperationID,
+		})
+	case media == nil:
+		fieldErrors = append(fieldErrors, utils.FieldError{
+			Field:   "content_type",
+			Rule:    "documented",
+			Param:   contentType,
+			Message: "content type " + contentType + " is not documented for status " + strconv.Itoa(status),
+		})
+	case isJSON(contentType):
+		issues, err := validator.ValidateJSON(media.Schema, c.Response().Body())
+		if err != nil {
+			return apperrors.Wrap(apperrors.CodeInternal, "Response is not valid JSON", err)
+		}
+		fieldErrors = issueFieldErrors(c.UserContext(), issues)
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	utils.RequestLogger(c).Error("Response does not match the OpenAPI document",
+		zap.String("operation", op.OperationID), zap.Any("errors", fieldErrors))
+	return apperrors.New(apperrors.CodeInternal, "Response does not match the OpenAPI document").
+		WithDetails(fieldErrors)
+}
+
+// issueFieldErrors converts schema issues into client-facing field errors
+func issueFieldErrors(ctx context.Context, issues []openapi.Issue) []utils.FieldError {
+	fieldErrors := make([]utils.FieldError, 0, len(issues))
+	for _, issue := range issues {
+		path := issue.Path
+		if path == "" {
+			path = "body"
+		}
+		field := path[strings.LastIndex(path, ".")+1:]
+		fieldErrors = append(fieldErrors, utils.FieldError{
+			Field:   path,
+			Rule:    issue.Rule,
+			Param:   issue.Param,
+			Message: i18n.RuleMessage(ctx, issue.Rule, field, issue.Param, issue.String),
+		})
+	}
+	return fieldErrors
+}
+
+// isJSON reports whether contentType is JSON or a +json media type
+func isJSON(contentType string) bool {
+	base, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (base == fiber.MIMEApplicationJSON || strings.HasSuffix(base, "+json"))
+}
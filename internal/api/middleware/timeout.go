@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+)
+
+// Timeout gives the request context a deadline of d. Services observe the
+// deadline through c.UserContext(); if it passes, the handler's result is
+// replaced with a REQUEST_TIMEOUT (504) error. Nested timeouts can only
+// shorten the deadline of an outer one. Requests to the exempt paths, such as
+// long-lived streams, get no deadline. A zero d disables the timeout.
+func Timeout(d time.Duration, exempt ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if d <= 0 || isExempt(c.Path(), exempt) {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return apperrors.ErrRequestTimeout
+		}
+		return err
+	}
+}
+
+// isExempt reports whether path is one of exempt, ignoring case and a
+// trailing slash like the router does
+func isExempt(path string, exempt []string) bool {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	for _, p := range exempt {
+		if strings.EqualFold(path, p) {
+			return true
+		}
+	}
+	return false
+}
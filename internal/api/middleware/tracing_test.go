@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/api/middleware"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// DryRun builds statements and runs the callbacks without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mr := miniredis.RunT(t)
+	port, _ := strconv.Atoi(mr.Port())
+	redisService, err := s.NewRedisService(context.Background(), config.RedisConfig{Host: mr.Host(), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { redisService.Close() })
+	redisService.AddHook(tracing.RedisHook{})
+
+	app := fiber.New()
+	app.Use(middleware.Tracing())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		var user models.User
+		if err := db.WithContext(c.UserContext()).Where("id = ?", c.Params("id")).First(&user).Error; err != nil {
+			return err
+		}
+		if err := redisService.Set(c.UserContext(), "user:"+c.Params("id"), "cached", 0); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/42", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusNoContent)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, ok := spans["GET /users/:id"]
+	if !ok {
+		t.Fatalf("no server span named after the route, got %v", spanNames(recorder.Ended()))
+	}
+	if server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span kind = %v", server.SpanKind())
+	}
+	if !hasAttribute(server, string(semconv.HTTPRouteKey), "/users/:id") {
+		t.Errorf("server span is missing http.route, got %v", server.Attributes())
+	}
+	for _, name := range []string{"db.query", "redis.set"} {
+		child, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span, got %v", name, spanNames(recorder.Ended()))
+			continue
+		}
+		if child.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("%s span is not a child of the server span", name)
+		}
+		if child.SpanContext().TraceID() != server.SpanContext().TraceID() {
+			t.Errorf("%s span is in another trace", name)
+		}
+	}
+	if query := spans["db.query"]; query != nil && !hasAttribute(query, string(semconv.DBCollectionNameKey), "users") {
+		t.Errorf("db.query span is missing the table, got %v", query.Attributes())
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	return names
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, key, value string) bool {
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key && attr.Value.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/api/middleware"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+var adminOperations = openapi.Operations{
+	"admin.emails.list": {
+		Summary: "List outbox emails",
+		Tags:    []string{"admin"},
+		Auth:    true,
+		Query: []openapi.Parameter{
+			{Name: "status", Description: "Only emails in this state: pending, sent or dead"},
+			{Name: "limit", Description: "Page size, 1 to 200 (default 50)", Example: 0},
+			{Name: "offset", Description: "Number of emails to skip", Example: 0},
+		},
+		Response: []m.OutboxEmail{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden},
+	},
+	"admin.emails.get": {
+		Summary:  "Get an outbox email",
+		Tags:     []string{"admin"},
+		Auth:     true,
+		Response: m.OutboxEmail{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.emails.retry": {
+		Summary:     "Retry an outbox email",
+		Description: "Schedules a pending or dead email for immediate delivery with a fresh set of attempts.",
+		Tags:        []string{"admin"},
+		Auth:        true,
+		Response:    m.OutboxEmail{},
+		Errors:      []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	"admin.jobs.queues": {
+		Summary:     "List job queues",
+		Description: "Counts the ready, scheduled, running and failed jobs of every queue.",
+		Tags:        []string{"admin"},
+		Auth:        true,
+		Response:    []jobs.QueueStats{},
+		Errors:      []int{fiber.StatusForbidden},
+	},
+	"admin.jobs.failed": {
+		Summary: "List failed jobs",
+		Tags:    []string{"admin"},
+		Auth:    true,
+		Query: []openapi.Parameter{
+			{Name: "queue", Description: "Queue name (default \"default\")"},
+			{Name: "limit", Description: "Page size, 1 to 200 (default 50)", Example: 0},
+			{Name: "offset", Description: "Number of jobs to skip", Example: 0},
+		},
+		Response: []jobs.Job{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden},
+	},
+	"admin.jobs.retry": {
+		Summary:     "Retry a failed job",
+		Description: "Moves a failed job back to its queue with a fresh set of attempts. Unique jobs can't be retried while another job with the same key is queued or running.",
+		Tags:        []string{"admin"},
+		Auth:        true,
+		Response:    jobs.Job{},
+		Errors:      []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	"admin.scheduler.runs": {
+		Summary: "List scheduled job runs",
+		Tags:    []string{"admin"},
+		Auth:    true,
+		Query: []openapi.Parameter{
+			{Name: "job", Description: "Only runs of this job, e.g. users.purge_deleted"},
+			{Name: "status", Description: "Only runs that succeeded or failed"},
+			{Name: "limit", Description: "Page size, 1 to 200 (default 50)", Example: 0},
+			{Name: "offset", Description: "Number of runs to skip", Example: 0},
+		},
+		Response: []m.ScheduledRun{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden},
+	},
+}
+
+func CreateAdminRoutes(api fiber.Router, emailOutboxHandler *h.EmailOutboxHandler, jobsHandler *h.JobsHandler, schedulerHandler *h.SchedulerHandler, authMiddleware fiber.Handler) fiber.Router {
+	admin := api.Group("/admin", authMiddleware, middleware.RequireRole(m.RoleAdmin))
+	admin.Get("/emails", emailOutboxHandler.List).Name("admin.emails.list")
+	admin.Get("/emails/:id", emailOutboxHandler.Get).Name("admin.emails.get")
+	admin.Post("/emails/:id/retry", emailOutboxHandler.Retry).Name("admin.emails.retry")
+	admin.Get("/jobs/queues", jobsHandler.Queues).Name("admin.jobs.queues")
+	admin.Get("/jobs/failed", jobsHandler.Failed).Name("admin.jobs.failed")
+	admin.Post("/jobs/:id/retry", jobsHandler.Retry).Name("admin.jobs.retry")
+	admin.Get("/scheduler/runs", schedulerHandler.Runs).Name("admin.scheduler.runs")
+	return admin
+}
@@ -3,10 +3,72 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
 )
 
-func CreateAuthRoutes(api fiber.Router, userHandler *h.AuthHandler) {
+var authOperations = openapi.Operations{
+	"auth.register": {
+		Summary:  "Register a new user",
+		Tags:     []string{"auth"},
+		Request:  m.RegisterInput{},
+		Response: m.AuthResponse{},
+		Errors:   []int{fiber.StatusConflict},
+	},
+	"auth.login": {
+		Summary:     "Log in with email and password",
+		Description: "Sign-ins from far away may fail with AUTH_STEP_UP_REQUIRED after a one-time code is sent; finish them with /auth/otp/verify.",
+		Tags:        []string{"auth"},
+		Request:     m.LoginInput{},
+		Response:    m.AuthResponse{},
+		Errors:      []int{fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusTooManyRequests},
+	},
+	"auth.otp.request": {
+		Summary:     "Send a one-time sign-in code",
+		Description: "Sends a code through the chosen channel. The response is the same whether or not the account exists, the channel can reach it or its quota is used up.",
+		Tags:        []string{"auth"},
+		Request:     m.OTPRequestInput{},
+		Response:    m.OTPRequestResponse{},
+	},
+	"auth.otp.verify": {
+		Summary:  "Log in with a one-time code",
+		Tags:     []string{"auth"},
+		Request:  m.OTPVerifyInput{},
+		Response: m.AuthResponse{},
+		Errors:   []int{fiber.StatusUnauthorized, fiber.StatusForbidden},
+	},
+	"auth.sessions.revoke": {
+		Summary:     "Sign out every session",
+		Description: "Uses the token of the link in a new sign-in alert. Every token issued to the user so far stops working and the alerted device is forgotten.",
+		Tags:        []string{"auth"},
+		Request:     m.RevokeSessionsInput{},
+		Response:    nil,
+		Errors:      []int{fiber.StatusUnauthorized},
+	},
+	"auth.phone.request": {
+		Summary:     "Text a code to verify the user's phone",
+		Description: "Sign-in codes are only sent by SMS to verified phone numbers. Counts against the same quotas as sign-in codes.",
+		Tags:        []string{"auth"},
+		Auth:        true,
+		Response:    nil,
+		Errors:      []int{fiber.StatusConflict, fiber.StatusTooManyRequests},
+	},
+	"auth.phone.verify": {
+		Summary:  "Verify the user's phone with a texted code",
+		Tags:     []string{"auth"},
+		Auth:     true,
+		Request:  m.PhoneVerifyInput{},
+		Response: m.UserResponse{},
+	},
+}
+
+func CreateAuthRoutes(api fiber.Router, userHandler *h.AuthHandler, middleware fiber.Handler) {
 	protected := api.Group("/auth")
-	protected.Post("/register", userHandler.Register)
-	protected.Post("/login", userHandler.Login)
+	protected.Post("/register", userHandler.Register).Name("auth.register")
+	protected.Post("/login", userHandler.Login).Name("auth.login")
+	protected.Post("/otp/request", userHandler.RequestOTP).Name("auth.otp.request")
+	protected.Post("/otp/verify", userHandler.VerifyOTP).Name("auth.otp.verify")
+	protected.Post("/sessions/revoke", userHandler.RevokeSessions).Name("auth.sessions.revoke")
+	protected.Post("/phone/request", middleware, userHandler.RequestPhoneVerification).Name("auth.phone.request")
+	protected.Post("/phone/verify", middleware, userHandler.VerifyPhone).Name("auth.phone.verify")
 }
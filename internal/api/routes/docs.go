@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+var docsOperations = openapi.Operations{
+	"docs.openapi": {
+		Summary:  "OpenAPI document",
+		Tags:     []string{"docs"},
+		Response: map[string]interface{}{},
+		Raw:      true,
+	},
+	"docs.ui": {
+		Summary:     "Swagger UI",
+		Tags:        []string{"docs"},
+		Raw:         true,
+		ContentType: fiber.MIMETextHTMLCharsetUTF8,
+	},
+}
+
+func CreateDocsRoutes(api fiber.Router, docsHandler *h.DocsHandler) {
+	api.Get("/openapi.json", docsHandler.OpenAPI).Name("docs.openapi")
+	api.Get("/docs", docsHandler.UI).Name("docs.ui")
+}
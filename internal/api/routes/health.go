@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+)
+
+var healthOperations = openapi.Operations{
+	"health": {
+		Summary: "Check that the server is accepting traffic",
+		Tags:    []string{"health"},
+		Errors:  []int{fiber.StatusServiceUnavailable},
+	},
+	"health.live": {
+		Summary: "Liveness probe",
+		Tags:    []string{"health"},
+	},
+	"health.ready": {
+		Summary:  "Readiness probe including dependency checks",
+		Tags:     []string{"health"},
+		Response: s.HealthReport{},
+		Errors:   []int{fiber.StatusServiceUnavailable},
+	},
+}
+
+func CreateHealthRoutes(api fiber.Router, healthHandler *h.HealthHandler) {
+	api.Get("/health", healthHandler.HealthCheck).Name("health")
+	health := api.Group("/health")
+	health.Get("/live", healthHandler.Live).Name("health.live")
+	health.Get("/ready", healthHandler.Ready).Name("health.ready")
+}
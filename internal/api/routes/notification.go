@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+var notificationOperations = openapi.Operations{
+	"notifications.list": {
+		Summary:     "List notifications",
+		Description: "Returns notifications newest first. Pass next_cursor as cursor to fetch the following page.",
+		Tags:        []string{"notifications"},
+		Auth:        true,
+		Query: []openapi.Parameter{
+			{Name: "cursor", Description: "next_cursor of the previous page"},
+			{Name: "limit", Description: "Page size, 1 to 100 (default 20)", Example: 0},
+			{Name: "unread", Description: "Only unread notifications", Example: false},
+			{Name: "category", Description: "Only notifications of this category: security or account"},
+		},
+		Response: m.NotificationPage{},
+		Errors:   []int{fiber.StatusBadRequest},
+	},
+	"notifications.read_all": {
+		Summary:  "Mark every notification read",
+		Tags:     []string{"notifications"},
+		Auth:     true,
+		Response: m.NotificationsMarked{},
+	},
+	"notifications.preferences.get": {
+		Summary:     "Get notification preferences",
+		Description: "Returns where notifications of each category are delivered: the inbox, email and push to open realtime streams.",
+		Tags:        []string{"notifications"},
+		Auth:        true,
+		Response:    []m.NotificationPreference{},
+	},
+	"notifications.preferences.update": {
+		Summary:     "Update notification preferences",
+		Description: "Changes the given channels of the given categories and returns every preference.",
+		Tags:        []string{"notifications"},
+		Auth:        true,
+		Request:     m.NotificationPreferencesInput{},
+		Response:    []m.NotificationPreference{},
+	},
+	"notifications.read": {
+		Summary:  "Mark a notification read",
+		Tags:     []string{"notifications"},
+		Auth:     true,
+		Response: m.Notification{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusNotFound},
+	},
+	"notifications.delete": {
+		Summary:  "Delete a notification",
+		Tags:     []string{"notifications"},
+		Auth:     true,
+		Response: nil,
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusNotFound},
+	},
+}
+
+func CreateNotificationRoutes(api fiber.Router, notificationHandler *h.NotificationHandler, authMiddleware fiber.Handler) {
+	notifications := api.Group("/notifications", authMiddleware)
+	notifications.Get("/", notificationHandler.List).Name("notifications.list")
+	notifications.Post("/read-all", notificationHandler.MarkAllRead).Name("notifications.read_all")
+	notifications.Get("/preferences", notificationHandler.Preferences).Name("notifications.preferences.get")
+	notifications.Put("/preferences", notificationHandler.UpdatePreferences).Name("notifications.preferences.update")
+	notifications.Post("/:id/read", notificationHandler.MarkRead).Name("notifications.read")
+	notifications.Delete("/:id", notificationHandler.Delete).Name("notifications.delete")
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+// streamQuery documents the query parameters shared by the event streams
+var streamQuery = []openapi.Parameter{
+	{Name: "access_token", Description: "JWT, for clients that can't send an Authorization header"},
+	{Name: "last_event_id", Description: "Resume after this event, like the Last-Event-ID header"},
+}
+
+var realtimeOperations = openapi.Operations{
+	"realtime.events": {
+		Summary:     "Stream the user's events",
+		Description: "Server-Sent Events stream of the authenticated user's events. Each event has an id to resume from, an event type and JSON data; comment lines are sent as heartbeats. The stream ends when the token expires or the user signs out everywhere.",
+		Tags:        []string{"realtime"},
+		Auth:        true,
+		Query:       streamQuery,
+		Raw:         true,
+		ContentType: "text/event-stream",
+		Errors:      []int{fiber.StatusTooManyRequests, fiber.StatusServiceUnavailable},
+	},
+	"realtime.ws": {
+		Summary:     "Stream the user's events over a WebSocket",
+		Description: "WebSocket carrying the authenticated user's events as JSON messages with id, type and data. The server sends pings as heartbeats, and closes with code 1008 when the token expires or the user signs out everywhere.",
+		Tags:        []string{"realtime"},
+		Auth:        true,
+		Query:       streamQuery,
+		Status:      fiber.StatusSwitchingProtocols,
+		Errors:      []int{fiber.StatusUpgradeRequired, fiber.StatusTooManyRequests, fiber.StatusServiceUnavailable},
+	},
+}
+
+// realtimePaths are the long-lived streams, exempt from the request timeout
+var realtimePaths = []string{apiPrefix + "/events", apiPrefix + "/ws"}
+
+func CreateRealtimeRoutes(api fiber.Router, realtimeHandler *h.RealtimeHandler, streamAuth fiber.Handler) {
+	api.Get("/events", streamAuth, realtimeHandler.Events).Name("realtime.events")
+	api.Get("/ws", streamAuth, realtimeHandler.WebSocket).Name("realtime.ws")
+}
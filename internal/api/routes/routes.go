@@ -1,36 +1,119 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/api/middleware"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/realtime"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/scheduler"
 	s "github.com/md-asharaf/go-fiber-boilerplate/internal/services"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.uber.org/zap"
 )
 
 type Services struct {
-	AuthService  *s.AuthService
-	UserService  *s.UserService
-	JWTService   *s.JWTService
-	RedisService *s.RedisService
-	EmailService *s.EmailService
-	OtpService   *s.OtpService
+	AuthService         *s.AuthService
+	UserService         *s.UserService
+	JWTService          *s.JWTService
+	RedisService        *s.RedisService
+	EmailService        *s.EmailService
+	OtpService          *s.OtpService
+	HealthService       *s.HealthService
+	JobClient           *jobs.Client
+	RunHistory          *scheduler.History
+	WebhookService      *s.WebhookService
+	RealtimeHub         *realtime.Hub
+	NotificationService *s.NotificationService
+}
+
+// Options holds the router's non-service dependencies
+type Options struct {
+	// Ready reports whether the server is accepting traffic and backs the health checks
+	Ready func() bool
+	// Logger is the base logger for request logs and request-scoped loggers
+	Logger *zap.Logger
+	// RequestTimeout is the default deadline for /api/v1 requests
+	RequestTimeout time.Duration
+	// ValidateRequests checks /api/v1 requests against the OpenAPI document
+	ValidateRequests bool
+	// ValidateResponses also checks responses against the document
+	ValidateResponses bool
+	// Routes registers additional routes under /api/v1, after the built-in ones
+	Routes func(api fiber.Router)
+	// Operations documents the routes added by Routes
+	Operations openapi.Operations
 }
 
-// SetupFiberRoutes configures all application routes for Fiber
-func SetupRoutes(app *fiber.App, services *Services) {
+// apiPrefix is the base path of every documented route
+const apiPrefix = "/api/v1"
+
+// SetupRoutes configures all application routes for Fiber and returns the
+// OpenAPI document describing them. It fails if a route under /api/v1 is not
+// documented in the operations of its route file.
+func SetupRoutes(app *fiber.App, services *Services, opts Options) (*openapi.Document, error) {
 	// Global middleware
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestLogger(opts.Logger))
+	app.Use(middleware.Locale())
+	app.Use(middleware.Metrics())
 	app.Use(middleware.CORS())
 
 	// Initialize handlers
 	authHandler := h.NewAuthHandler(services.AuthService)
 	userHandler := h.NewUserHandler(services.UserService)
+	healthHandler := h.NewHealthHandler(services.HealthService, opts.Ready)
+	docsHandler := h.NewDocsHandler(apiPrefix + "/openapi.json")
+	emailOutboxHandler := h.NewEmailOutboxHandler(services.EmailService)
+	jobsHandler := h.NewJobsHandler(services.JobClient)
+	schedulerHandler := h.NewSchedulerHandler(services.RunHistory)
+	webhookHandler := h.NewWebhookHandler(services.WebhookService)
+	realtimeHandler := h.NewRealtimeHandler(services.RealtimeHub)
+	notificationHandler := h.NewNotificationHandler(services.NotificationService)
 
 	authMiddleware := middleware.JWTAuth(services.JWTService, services.UserService)
+	streamAuth := middleware.StreamAuth(services.JWTService, services.UserService)
 
-	api := app.Group("/api/v1")
-	// Health check (no auth required)
-	api.Get("/health", h.HealthCheck)
-
-	CreateAuthRoutes(api, authHandler)
+	api := app.Group(apiPrefix, middleware.Timeout(opts.RequestTimeout, realtimePaths...))
+	// The validator is loaded with the document once every route is registered
+	validator := openapi.NewValidator()
+	if opts.ValidateRequests || opts.ValidateResponses {
+		api.Use(middleware.OpenAPIValidation(validator, opts.ValidateResponses))
+	}
+	// Health checks (no auth required)
+	CreateHealthRoutes(api, healthHandler)
+	CreateAuthRoutes(api, authHandler, authMiddleware)
 	CreateUserRoutes(api, userHandler, authMiddleware)
+	CreateDocsRoutes(api, docsHandler)
+	// Other admin routes join the group, which already requires an admin
+	admin := CreateAdminRoutes(api, emailOutboxHandler, jobsHandler, schedulerHandler, authMiddleware)
+	CreateWebhookRoutes(admin, webhookHandler)
+	CreateRealtimeRoutes(api, realtimeHandler, streamAuth)
+	CreateNotificationRoutes(api, notificationHandler, authMiddleware)
+	if opts.Routes != nil {
+		opts.Routes(api)
+	}
+
+	document, err := openapi.Generate(app, openapi.Config{
+		Info: openapi.Info{
+			Title:   "go-fiber-boilerplate API",
+			Version: "1.0.0",
+		},
+		Prefix:   apiPrefix,
+		Envelope: utils.SuccessResponse{},
+		ErrorBodies: map[string]interface{}{
+			fiber.MIMEApplicationJSON:        utils.ErrorResponse{},
+			utils.MIMEApplicationProblemJSON: utils.ProblemDetails{},
+		},
+		Operations: openapi.Merge(healthOperations, authOperations, userOperations, docsOperations, adminOperations, webhookOperations, realtimeOperations, notificationOperations, opts.Operations),
+	})
+	if err != nil {
+		return nil, err
+	}
+	docsHandler.SetDocument(document)
+	validator.Load(document)
+	return document, nil
 }
@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/api/middleware"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.uber.org/zap"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)\??`)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	app := fiber.New()
+	document, err := SetupRoutes(app, &Services{}, Options{Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked := 0
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, apiPrefix) {
+			continue
+		}
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		item, ok := document.Paths[path]
+		if !ok {
+			t.Errorf("%s %s: path %s is missing from the document", route.Method, route.Path, path)
+			continue
+		}
+		if item.Operation(route.Method) == nil {
+			t.Errorf("%s %s: method is missing from the document", route.Method, route.Path)
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatal("no routes under " + apiPrefix)
+	}
+}
+
+func TestUndocumentedRouteFails(t *testing.T) {
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	tests := []struct {
+		name       string
+		operations openapi.Operations
+		wantErr    bool
+	}{
+		{name: "documented", operations: openapi.Operations{"extra.ping": {Summary: "Ping"}}},
+		{name: "undocumented", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SetupRoutes(fiber.New(), &Services{}, Options{
+				Logger: zap.NewNop(),
+				Routes: func(api fiber.Router) {
+					api.Get("/extra/ping", ok).Name("extra.ping")
+				},
+				Operations: tt.operations,
+			})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "GET "+apiPrefix+"/extra/ping") {
+					t.Fatalf("err = %v, want the undocumented route named", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type pingResponse struct {
+	Pong bool `json:"pong" validate:"required"`
+}
+
+func TestResponseValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       interface{}
+		wantStatus int
+	}{
+		{name: "matching", data: pingResponse{Pong: true}, wantStatus: fiber.StatusOK},
+		{name: "wrong type", data: map[string]string{"pong": "yes"}, wantStatus: fiber.StatusInternalServerError},
+		{name: "missing field", data: map[string]string{}, wantStatus: fiber.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(false)})
+			_, err := SetupRoutes(app, &Services{}, Options{
+				Logger:            zap.NewNop(),
+				ValidateResponses: true,
+				Routes: func(api fiber.Router) {
+					api.Get("/extra/ping", func(c *fiber.Ctx) error {
+						return utils.WriteSuccessResponse(c, tt.data, "pong")
+					}).Name("extra.ping")
+				},
+				Operations: openapi.Operations{"extra.ping": {Summary: "Ping", Response: pingResponse{}}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, apiPrefix+"/extra/ping", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == fiber.StatusOK {
+				return
+			}
+			var body utils.ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != string(apperrors.CodeInternal) || body.Details == nil {
+				t.Errorf("body = %+v, want an %s error listing the mismatches", body, apperrors.CodeInternal)
+			}
+		})
+	}
+}
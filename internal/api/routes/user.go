@@ -3,9 +3,20 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
 )
 
+var userOperations = openapi.Operations{
+	"user.me": {
+		Summary:  "Get the authenticated user",
+		Tags:     []string{"user"},
+		Auth:     true,
+		Response: m.UserResponse{},
+	},
+}
+
 func CreateUserRoutes(api fiber.Router, userHandler *h.UserHandler, middleware fiber.Handler) {
 	protected := api.Group("/user", middleware)
-	protected.Get("/me", userHandler.Me)
+	protected.Get("/me", userHandler.Me).Name("user.me")
 }
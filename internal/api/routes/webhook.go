@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	h "github.com/md-asharaf/go-fiber-boilerplate/internal/api/handlers"
+	m "github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/openapi"
+)
+
+var webhookOperations = openapi.Operations{
+	"admin.webhooks.list": {
+		Summary: "List webhook endpoints",
+		Tags:    []string{"webhooks"},
+		Auth:    true,
+		Query: []openapi.Parameter{
+			{Name: "limit", Description: "Page size, 1 to 200 (default 50)", Example: 0},
+			{Name: "offset", Description: "Number of endpoints to skip", Example: 0},
+		},
+		Response: []m.WebhookEndpoint{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden},
+	},
+	"admin.webhooks.create": {
+		Summary:     "Create a webhook endpoint",
+		Description: "The response contains the signing secret, which is not shown again.",
+		Tags:        []string{"webhooks"},
+		Auth:        true,
+		Request:     m.WebhookEndpointInput{},
+		Response:    m.WebhookEndpointSecret{},
+		Errors:      []int{fiber.StatusForbidden},
+	},
+	"admin.webhooks.get": {
+		Summary:  "Get a webhook endpoint",
+		Tags:     []string{"webhooks"},
+		Auth:     true,
+		Response: m.WebhookEndpoint{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.update": {
+		Summary:  "Update a webhook endpoint",
+		Tags:     []string{"webhooks"},
+		Auth:     true,
+		Request:  m.WebhookEndpointInput{},
+		Response: m.WebhookEndpoint{},
+		Errors:   []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.delete": {
+		Summary:  "Delete a webhook endpoint",
+		Tags:     []string{"webhooks"},
+		Auth:     true,
+		Response: nil,
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.test": {
+		Summary:     "Send a test event",
+		Description: "Sends a webhook.test event right away and returns the delivery with its attempt.",
+		Tags:        []string{"webhooks"},
+		Auth:        true,
+		Response:    m.WebhookDelivery{},
+		Errors:      []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.deliveries": {
+		Summary: "List the deliveries of a webhook endpoint",
+		Tags:    []string{"webhooks"},
+		Auth:    true,
+		Query: []openapi.Parameter{
+			{Name: "status", Description: "Only deliveries in this state: pending, succeeded or failed"},
+			{Name: "limit", Description: "Page size, 1 to 200 (default 50)", Example: 0},
+			{Name: "offset", Description: "Number of deliveries to skip", Example: 0},
+		},
+		Response: []m.WebhookDelivery{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.deliveries.get": {
+		Summary:  "Get a webhook delivery",
+		Tags:     []string{"webhooks"},
+		Auth:     true,
+		Response: m.WebhookDelivery{},
+		Errors:   []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"admin.webhooks.deliveries.replay": {
+		Summary:     "Replay a failed webhook delivery",
+		Description: "Queues the delivery again with a fresh set of attempts. The payload is unchanged.",
+		Tags:        []string{"webhooks"},
+		Auth:        true,
+		Response:    m.WebhookDelivery{},
+		Errors:      []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+}
+
+func CreateWebhookRoutes(admin fiber.Router, webhookHandler *h.WebhookHandler) {
+	webhooks := admin.Group("/webhooks")
+	webhooks.Get("/", webhookHandler.List).Name("admin.webhooks.list")
+	webhooks.Post("/", webhookHandler.Create).Name("admin.webhooks.create")
+	webhooks.Get("/deliveries/:id", webhookHandler.Delivery).Name("admin.webhooks.deliveries.get")
+	webhooks.Post("/deliveries/:id/replay", webhookHandler.Replay).Name("admin.webhooks.deliveries.replay")
+	webhooks.Get("/:id", webhookHandler.Get).Name("admin.webhooks.get")
+	webhooks.Put("/:id", webhookHandler.Update).Name("admin.webhooks.update")
+	webhooks.Delete("/:id", webhookHandler.Delete).Name("admin.webhooks.delete")
+	webhooks.Post("/:id/test", webhookHandler.Test).Name("admin.webhooks.test")
+	webhooks.Get("/:id/deliveries", webhookHandler.Deliveries).Name("admin.webhooks.deliveries")
+}
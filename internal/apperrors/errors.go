@@ -0,0 +1,143 @@
+// Package apperrors defines typed domain errors with stable machine-readable
+// codes and the HTTP status each code maps to.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error code returned to clients
+type Code string
+
+// Error codes
+const (
+	CodeInvalidInput           Code = "INVALID_INPUT"
+	CodeValidationFailed       Code = "VALIDATION_FAILED"
+	CodeAuthInvalidCredentials Code = "AUTH_INVALID_CREDENTIALS"
+	CodeAuthAccountInactive    Code = "AUTH_ACCOUNT_INACTIVE"
+	CodeAuthUnauthorized       Code = "AUTH_UNAUTHORIZED"
+	CodeAuthInvalidToken       Code = "AUTH_INVALID_TOKEN"
+	CodeAuthStepUpRequired     Code = "AUTH_STEP_UP_REQUIRED"
+	CodeForbidden              Code = "FORBIDDEN"
+	CodeUserExists             Code = "USER_EXISTS"
+	CodeUserNotFound           Code = "USER_NOT_FOUND"
+	CodeConflict               Code = "CONFLICT"
+	CodeNotFound               Code = "NOT_FOUND"
+	CodeMethodNotAllowed       Code = "METHOD_NOT_ALLOWED"
+	CodePayloadTooLarge        Code = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType   Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUpgradeRequired        Code = "UPGRADE_REQUIRED"
+	CodeTooManyRequests        Code = "TOO_MANY_REQUESTS"
+	CodeRequestTimeout         Code = "REQUEST_TIMEOUT"
+	CodeRequestCancelled       Code = "REQUEST_CANCELLED"
+	CodeServiceUnavailable     Code = "SERVICE_UNAVAILABLE"
+	CodeInternal               Code = "INTERNAL_ERROR"
+)
+
+var statusByCode = map[Code]int{
+	CodeInvalidInput:           http.StatusBadRequest,
+	CodeValidationFailed:       http.StatusBadRequest,
+	CodeAuthInvalidCredentials: http.StatusUnauthorized,
+	CodeAuthAccountInactive:    http.StatusForbidden,
+	CodeAuthUnauthorized:       http.StatusUnauthorized,
+	CodeAuthInvalidToken:       http.StatusUnauthorized,
+	CodeAuthStepUpRequired:     http.StatusUnauthorized,
+	CodeForbidden:              http.StatusForbidden,
+	CodeUserExists:             http.StatusConflict,
+	CodeUserNotFound:           http.StatusNotFound,
+	CodeConflict:               http.StatusConflict,
+	CodeNotFound:               http.StatusNotFound,
+	CodeMethodNotAllowed:       http.StatusMethodNotAllowed,
+	CodePayloadTooLarge:        http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMediaType:   http.StatusUnsupportedMediaType,
+	CodeUpgradeRequired:        http.StatusUpgradeRequired,
+	CodeTooManyRequests:        http.StatusTooManyRequests,
+	CodeRequestTimeout:         http.StatusGatewayTimeout,
+	CodeRequestCancelled:       http.StatusServiceUnavailable,
+	CodeServiceUnavailable:     http.StatusServiceUnavailable,
+	CodeInternal:               http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status for code, or 500 for unknown codes
+func HTTPStatus(code Code) int {
+	if status, ok := statusByCode[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is a domain error that is safe to show to clients. Err holds the
+// underlying cause, which is logged but never rendered.
+type Error struct {
+	Code    Code
+	Message string
+	Details interface{}
+	Err     error
+}
+
+// New creates an error with a client-facing message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates an error with a client-facing message and an internal cause
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return string(e.Code) + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code, so wrapped copies of a sentinel match it
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Status returns the HTTP status for the error's code
+func (e *Error) Status() int {
+	return HTTPStatus(e.Code)
+}
+
+// WithDetails returns a copy of e carrying client-facing details
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// As returns the *Error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Sentinel errors returned by the services
+var (
+	ErrInvalidCredentials   = New(CodeAuthInvalidCredentials, "Invalid credentials")
+	ErrAccountInactive      = New(CodeAuthAccountInactive, "Account is inactive")
+	ErrUnauthorized         = New(CodeAuthUnauthorized, "Missing or invalid Authorization header")
+	ErrInvalidToken         = New(CodeAuthInvalidToken, "Invalid or expired token")
+	ErrStepUpRequired       = New(CodeAuthStepUpRequired, "Unusual sign-in: enter the one-time code that was sent to you")
+	ErrForbidden            = New(CodeForbidden, "You do not have permission to perform this action")
+	ErrUserExists           = New(CodeUserExists, "User already exists")
+	ErrUserNotFound         = New(CodeUserNotFound, "User not found")
+	ErrNotFound             = New(CodeNotFound, "Resource not found")
+	ErrUnsupportedMediaType = New(CodeUnsupportedMediaType, "Unsupported media type")
+	ErrUpgradeRequired      = New(CodeUpgradeRequired, "This endpoint requires a WebSocket connection")
+	ErrRequestTimeout       = New(CodeRequestTimeout, "Request timed out")
+	ErrRequestCancelled     = New(CodeRequestCancelled, "Request cancelled")
+	ErrInternal             = New(CodeInternal, "Internal server error")
+)
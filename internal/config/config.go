@@ -4,31 +4,52 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	SMTP     SMTPConfig
-	Logger   LoggerConfig
-	App      AppConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	SMTP        SMTPConfig
+	Email       EmailConfig
+	OTP         OTPConfig
+	SMS         SMSConfig
+	Jobs        JobsConfig
+	Scheduler   SchedulerConfig
+	Webhooks    WebhooksConfig
+	Events      EventsConfig
+	Realtime    RealtimeConfig
+	LoginAlerts LoginAlertsConfig
+	Logger      LoggerConfig
+	Health      HealthConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
+	App         AppConfig
 }
 
 // ServerConfig holds server-specific configuration
 type ServerConfig struct {
-	Host string
-	Port int
+	Host            string
+	Port            int
+	IdleTimeout     time.Duration
+	RequestTimeout  time.Duration
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	URL string
+	URL         string
+	AutoMigrate bool
 }
 
 // RedisConfig holds Redis configuration
@@ -41,15 +62,161 @@ type RedisConfig struct {
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Secret string
+	// SecretGrace is how long tokens signed with Secret keep validating after
+	// the first signing key is rotated in
+	SecretGrace time.Duration
 }
 
-// EmailConfig holds email configuration
+// SMTPConfig holds SMTP server configuration, used by the smtp transport
 type SMTPConfig struct {
 	Host     string
 	Port     int
 	Username string
 	Password string
-	From     string
+	// TLSMode is implicit (TLS from the first byte), starttls (required) or none
+	TLSMode string
+	// CAFile is a PEM bundle trusted instead of the system roots
+	CAFile string
+	// Timeout bounds connecting and each SMTP command
+	Timeout time.Duration
+	// PoolSize is the number of connections kept open for reuse
+	PoolSize int
+	// RateLimit caps emails sent per minute, 0 for no limit
+	RateLimit int
+	DKIM      DKIMConfig
+}
+
+// DKIMConfig holds DKIM signing configuration. Signing is enabled when
+// PrivateKeyFile is set.
+type DKIMConfig struct {
+	Domain         string
+	Selector       string
+	PrivateKeyFile string
+}
+
+// EmailConfig holds email rendering and delivery configuration
+type EmailConfig struct {
+	// Transport is how emails are delivered: smtp, log, file or memory
+	Transport string
+	// From is the sender address of every email
+	From string
+	// FileDir is where the file transport writes .eml files
+	FileDir string
+	// TemplatesDir overrides the embedded email templates file by file
+	TemplatesDir string
+	// OutboxPollInterval is how often the worker looks for due emails
+	OutboxPollInterval time.Duration
+	// OutboxBatchSize caps the emails claimed per poll
+	OutboxBatchSize int
+	// OutboxConcurrency is how many emails of a batch are sent in parallel
+	OutboxConcurrency int
+	// MaxAttempts is how many sends are tried before an email is dead-lettered
+	MaxAttempts int
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between attempts
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// OTPConfig holds one-time code configuration
+type OTPConfig struct {
+	// Channels lists the enabled delivery channels, the first is the default
+	Channels []string
+	TTL      time.Duration
+	// MaxAttempts is how many wrong codes invalidate the current one
+	MaxAttempts int
+	// HourlyLimit and DailyLimit cap the codes sent to one destination
+	HourlyLimit int
+	DailyLimit  int
+	// SMSHourlyLimitPerIP caps the texts requested from one client IP, and
+	// SMSDailyLimit the texts sent in total
+	SMSHourlyLimitPerIP int
+	SMSDailyLimit       int
+}
+
+// SMSConfig holds SMS gateway configuration, used by the sms OTP channel
+type SMSConfig struct {
+	// Provider is http (a JSON gateway) or fake (logged and kept in memory)
+	Provider   string
+	GatewayURL string
+	APIKey     string
+	From       string
+	Timeout    time.Duration
+}
+
+// JobsConfig holds background job queue configuration
+type JobsConfig struct {
+	// WorkerEnabled runs the job worker inside this process
+	WorkerEnabled bool
+	// Queues are polled in order, so earlier queues take priority
+	Queues            []string
+	Concurrency       int
+	PollInterval      time.Duration
+	VisibilityTimeout time.Duration
+	MaxAttempts       int
+	RetryBaseDelay    time.Duration
+	RetryMaxDelay     time.Duration
+}
+
+// SchedulerConfig holds the schedules of the maintenance jobs. An empty
+// schedule disables the job.
+type SchedulerConfig struct {
+	// Enabled runs the scheduler inside the server; the worker command always runs it
+	Enabled bool
+	// Timeout bounds a single run of any job
+	Timeout              time.Duration
+	PurgeDeletedUsers    string
+	DeletedUserRetention time.Duration
+	PruneEmailOutbox     string
+	EmailOutboxRetention time.Duration
+	PruneRuns            string
+	RunRetention         time.Duration
+	RotateJWTKey         string
+	JWTKeyGrace          time.Duration
+	CleanupSigningKeys   string
+}
+
+// WebhooksConfig holds outgoing webhook configuration
+type WebhooksConfig struct {
+	// Timeout bounds a single delivery request
+	Timeout     time.Duration
+	MaxAttempts int
+	// AllowPrivateNetworks lets endpoints resolve to loopback, private and
+	// link-local addresses, for receivers running next to the app
+	AllowPrivateNetworks bool
+}
+
+// EventsConfig holds event bus configuration
+type EventsConfig struct {
+	// RelayInterval is how often the outbox is checked for unpublished events
+	RelayInterval time.Duration
+}
+
+// RealtimeConfig holds WebSocket and Server-Sent Events configuration
+type RealtimeConfig struct {
+	HeartbeatInterval time.Duration
+	// MaxConnections caps each user's open streams across replicas
+	MaxConnections int
+	// HistorySize and HistoryTTL bound the events kept for resuming streams
+	HistorySize int
+	HistoryTTL  time.Duration
+}
+
+// LoginAlertsConfig holds new-device and suspicious sign-in alert configuration
+type LoginAlertsConfig struct {
+	// Enabled remembers users' devices and alerts them about sign-ins from new ones
+	Enabled bool
+	// GeoIPDatabase is a MaxMind-format city database locating sign-ins; empty leaves locations out
+	GeoIPDatabase string
+	// MaxDevices caps the devices remembered per user
+	MaxDevices int
+	// RevokeURL is the page behind the "this wasn't me" link, which gets a token parameter
+	RevokeURL string
+	// RevokeTTL is how long that link works
+	RevokeTTL time.Duration
+	// MaxTravelSpeed in km/h flags sign-ins too far from the previous one to have travelled
+	MaxTravelSpeed float64
+	// StepUp requires a one-time code to complete flagged password sign-ins
+	StepUp bool
 }
 
 // LoggingConfig holds logging configuration
@@ -57,9 +224,38 @@ type LoggerConfig struct {
 	Level string
 }
 
+// HealthConfig holds readiness probe configuration
+type HealthConfig struct {
+	Timeout   time.Duration
+	CacheTTL  time.Duration
+	CheckSMTP bool
+}
+
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	Enabled bool
+	Port    int // 0 serves /metrics on the main server port
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration. The OTLP endpoint is
+// read by the exporter from the standard OTEL_EXPORTER_OTLP_* variables.
+type TracingConfig struct {
+	Enabled     bool
+	ServiceName string
+	SampleRatio float64
+}
+
 // AppConfig holds application-specific configuration
 type AppConfig struct {
+	// Name is shown in emails and documentation
+	Name        string
 	Environment string
+	// ErrorFormat is the default error body: "json" (ErrorResponse) or "problem" (RFC 9457)
+	ErrorFormat string
+	// ValidateRequests checks /api/v1 requests against the OpenAPI document
+	ValidateRequests bool
+	// ValidateResponses also checks responses; meant for development and tests
+	ValidateResponses bool
 }
 
 // Load loads configuration from environment variables
@@ -78,11 +274,26 @@ func Load() (*Config, error) {
 	if cfg.Server.Port, err = getEnvAsIntRequired("SERVER_PORT"); err != nil {
 		errs = append(errs, fmt.Errorf("server port: %w", err))
 	}
+	if cfg.Server.IdleTimeout, err = getEnvAsDuration("SERVER_IDLE_TIMEOUT", time.Minute); err != nil {
+		errs = append(errs, fmt.Errorf("server idle timeout: %w", err))
+	}
+	if cfg.Server.RequestTimeout, err = getEnvAsDuration("SERVER_REQUEST_TIMEOUT", 15*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("server request timeout: %w", err))
+	}
+	if cfg.Server.DrainDelay, err = getEnvAsDuration("SERVER_DRAIN_DELAY", 5*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("server drain delay: %w", err))
+	}
+	if cfg.Server.ShutdownTimeout, err = getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("server shutdown timeout: %w", err))
+	}
 
 	// Database Config - All are required
 	if cfg.Database.URL, err = getEnvRequired("DATABASE_URL"); err != nil {
 		errs = append(errs, fmt.Errorf("database url: %w", err))
 	}
+	if cfg.Database.AutoMigrate, err = getEnvAsBool("DATABASE_AUTO_MIGRATE", true); err != nil {
+		errs = append(errs, fmt.Errorf("database auto migrate: %w", err))
+	}
 
 	// Redis Config
 	if cfg.Redis.Host, err = getEnvRequired("REDIS_HOST"); err != nil {
@@ -97,29 +308,338 @@ func Load() (*Config, error) {
 	if cfg.JWT.Secret, err = getEnvRequired("JWT_SECRET"); err != nil {
 		errs = append(errs, fmt.Errorf("jwt secret: %w", err))
 	}
+	if cfg.JWT.SecretGrace, err = getEnvAsDuration("JWT_SECRET_GRACE", 7*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("jwt secret grace: %w", err))
+	}
 
-	// SMTP Config - All are required
-	if cfg.SMTP.Host, err = getEnvRequired("SMTP_HOST"); err != nil {
-		errs = append(errs, fmt.Errorf("smtp host: %w", err))
+	// Email Config
+	cfg.Email.Transport = getEnv("EMAIL_TRANSPORT", "smtp")
+	switch cfg.Email.Transport {
+	case "smtp", "log", "file", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("email transport: must be smtp, log, file or memory, got %q", cfg.Email.Transport))
+	}
+	// SMTP_FROM_EMAIL is still accepted for existing deployments
+	cfg.Email.From = getEnv("EMAIL_FROM", getEnv("SMTP_FROM_EMAIL", ""))
+	if cfg.Email.From == "" {
+		errs = append(errs, errors.New("email from: missing required environment variable: EMAIL_FROM"))
+	}
+	cfg.Email.FileDir = getEnv("EMAIL_FILE_DIR", "tmp/emails")
+	cfg.Email.TemplatesDir = getEnv("EMAIL_TEMPLATES_DIR", "")
+	if cfg.Email.OutboxPollInterval, err = getEnvAsDuration("EMAIL_OUTBOX_POLL_INTERVAL", 2*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("email outbox poll interval: %w", err))
+	} else if cfg.Email.OutboxPollInterval <= 0 {
+		errs = append(errs, errors.New("email outbox poll interval: must be positive"))
+	}
+	if cfg.Email.OutboxBatchSize, err = getEnvAsInt("EMAIL_OUTBOX_BATCH_SIZE", 20); err != nil {
+		errs = append(errs, fmt.Errorf("email outbox batch size: %w", err))
 	}
-	if cfg.SMTP.Port, err = getEnvAsIntRequired("SMTP_PORT"); err != nil {
-		errs = append(errs, fmt.Errorf("smtp port: %w", err))
+	if cfg.Email.OutboxConcurrency, err = getEnvAsInt("EMAIL_OUTBOX_CONCURRENCY", 4); err != nil {
+		errs = append(errs, fmt.Errorf("email outbox concurrency: %w", err))
 	}
-	if cfg.SMTP.Username, err = getEnvRequired("SMTP_USERNAME"); err != nil {
-		errs = append(errs, fmt.Errorf("smtp username: %w", err))
+	if cfg.Email.MaxAttempts, err = getEnvAsInt("EMAIL_MAX_ATTEMPTS", 8); err != nil {
+		errs = append(errs, fmt.Errorf("email max attempts: %w", err))
 	}
-	if cfg.SMTP.Password, err = getEnvRequired("SMTP_PASSWORD"); err != nil {
-		errs = append(errs, fmt.Errorf("smtp password: %w", err))
+	if cfg.Email.RetryBaseDelay, err = getEnvAsDuration("EMAIL_RETRY_BASE_DELAY", 30*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("email retry base delay: %w", err))
+	} else if cfg.Email.RetryBaseDelay <= 0 {
+		errs = append(errs, errors.New("email retry base delay: must be positive"))
 	}
-	if cfg.SMTP.From, err = getEnvRequired("SMTP_FROM_EMAIL"); err != nil {
-		errs = append(errs, fmt.Errorf("smtp from email: %w", err))
+	if cfg.Email.RetryMaxDelay, err = getEnvAsDuration("EMAIL_RETRY_MAX_DELAY", time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("email retry max delay: %w", err))
+	} else if cfg.Email.RetryMaxDelay <= 0 {
+		errs = append(errs, errors.New("email retry max delay: must be positive"))
+	}
+	if cfg.Email.OutboxBatchSize < 1 || cfg.Email.OutboxConcurrency < 1 || cfg.Email.MaxAttempts < 1 {
+		errs = append(errs, errors.New("email outbox: batch size, concurrency and max attempts must be at least 1"))
+	}
+
+	// SMTP Config - Required by the smtp transport only
+	if cfg.Email.Transport == "smtp" {
+		if cfg.SMTP.Host, err = getEnvRequired("SMTP_HOST"); err != nil {
+			errs = append(errs, fmt.Errorf("smtp host: %w", err))
+		}
+		if cfg.SMTP.Port, err = getEnvAsIntRequired("SMTP_PORT"); err != nil {
+			errs = append(errs, fmt.Errorf("smtp port: %w", err))
+		}
+		// Relays that trust the network need no credentials
+		cfg.SMTP.Username = getEnv("SMTP_USERNAME", "")
+		cfg.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+		if cfg.SMTP.Username == "" && cfg.SMTP.Password != "" {
+			errs = append(errs, errors.New("smtp password: set without SMTP_USERNAME"))
+		}
+		// Port 465 is implicit TLS by convention, everything else must upgrade
+		defaultTLSMode := "starttls"
+		if cfg.SMTP.Port == 465 {
+			defaultTLSMode = "implicit"
+		}
+		cfg.SMTP.TLSMode = getEnv("SMTP_TLS", defaultTLSMode)
+		switch cfg.SMTP.TLSMode {
+		case "implicit", "starttls", "none":
+		default:
+			errs = append(errs, fmt.Errorf("smtp tls: must be implicit, starttls or none, got %q", cfg.SMTP.TLSMode))
+		}
+		cfg.SMTP.CAFile = getEnv("SMTP_CA_FILE", "")
+		if cfg.SMTP.Timeout, err = getEnvAsDuration("SMTP_TIMEOUT", 10*time.Second); err != nil {
+			errs = append(errs, fmt.Errorf("smtp timeout: %w", err))
+		}
+		if cfg.SMTP.PoolSize, err = getEnvAsInt("SMTP_POOL_SIZE", 4); err != nil {
+			errs = append(errs, fmt.Errorf("smtp pool size: %w", err))
+		} else if cfg.SMTP.PoolSize < 1 {
+			errs = append(errs, errors.New("smtp pool size: must be at least 1"))
+		}
+		if cfg.SMTP.RateLimit, err = getEnvAsInt("SMTP_RATE_LIMIT", 0); err != nil {
+			errs = append(errs, fmt.Errorf("smtp rate limit: %w", err))
+		}
+
+		cfg.SMTP.DKIM.PrivateKeyFile = getEnv("DKIM_PRIVATE_KEY_FILE", "")
+		if cfg.SMTP.DKIM.PrivateKeyFile != "" {
+			if cfg.SMTP.DKIM.Domain, err = getEnvRequired("DKIM_DOMAIN"); err != nil {
+				errs = append(errs, fmt.Errorf("dkim domain: %w", err))
+			}
+			if cfg.SMTP.DKIM.Selector, err = getEnvRequired("DKIM_SELECTOR"); err != nil {
+				errs = append(errs, fmt.Errorf("dkim selector: %w", err))
+			}
+		}
+	}
+
+	// OTP Config
+	cfg.OTP.Channels = strings.Split(getEnv("OTP_CHANNELS", "email"), ",")
+	for i, channel := range cfg.OTP.Channels {
+		channel = strings.TrimSpace(channel)
+		cfg.OTP.Channels[i] = channel
+		switch channel {
+		case "email", "sms", "console":
+		default:
+			errs = append(errs, fmt.Errorf("otp channels: must be email, sms or console, got %q", channel))
+		}
+	}
+	if cfg.OTP.TTL, err = getEnvAsDuration("OTP_TTL", 10*time.Minute); err != nil {
+		errs = append(errs, fmt.Errorf("otp ttl: %w", err))
+	}
+	if cfg.OTP.MaxAttempts, err = getEnvAsInt("OTP_MAX_ATTEMPTS", 5); err != nil {
+		errs = append(errs, fmt.Errorf("otp max attempts: %w", err))
+	}
+	if cfg.OTP.HourlyLimit, err = getEnvAsInt("OTP_DESTINATION_HOURLY_LIMIT", 5); err != nil {
+		errs = append(errs, fmt.Errorf("otp destination hourly limit: %w", err))
+	}
+	if cfg.OTP.DailyLimit, err = getEnvAsInt("OTP_DESTINATION_DAILY_LIMIT", 10); err != nil {
+		errs = append(errs, fmt.Errorf("otp destination daily limit: %w", err))
+	}
+	if cfg.OTP.SMSHourlyLimitPerIP, err = getEnvAsInt("OTP_SMS_IP_HOURLY_LIMIT", 10); err != nil {
+		errs = append(errs, fmt.Errorf("otp sms ip hourly limit: %w", err))
+	}
+	if cfg.OTP.SMSDailyLimit, err = getEnvAsInt("OTP_SMS_DAILY_LIMIT", 1000); err != nil {
+		errs = append(errs, fmt.Errorf("otp sms daily limit: %w", err))
+	}
+
+	// SMS Config - Required by the sms OTP channel only
+	if slices.Contains(cfg.OTP.Channels, "sms") {
+		cfg.SMS.Provider = getEnv("SMS_PROVIDER", "fake")
+		switch cfg.SMS.Provider {
+		case "fake":
+		case "http":
+			if cfg.SMS.GatewayURL, err = getEnvRequired("SMS_GATEWAY_URL"); err != nil {
+				errs = append(errs, fmt.Errorf("sms gateway url: %w", err))
+			}
+			cfg.SMS.APIKey = getEnv("SMS_API_KEY", "")
+			cfg.SMS.From = getEnv("SMS_FROM", "")
+		default:
+			errs = append(errs, fmt.Errorf("sms provider: must be http or fake, got %q", cfg.SMS.Provider))
+		}
+		if cfg.SMS.Timeout, err = getEnvAsDuration("SMS_TIMEOUT", 10*time.Second); err != nil {
+			errs = append(errs, fmt.Errorf("sms timeout: %w", err))
+		}
+	}
+
+	// Jobs Config
+	if cfg.Jobs.WorkerEnabled, err = getEnvAsBool("JOBS_WORKER_ENABLED", true); err != nil {
+		errs = append(errs, fmt.Errorf("jobs worker enabled: %w", err))
+	}
+	cfg.Jobs.Queues = strings.Split(getEnv("JOBS_QUEUES", "default,maintenance"), ",")
+	for i, queue := range cfg.Jobs.Queues {
+		cfg.Jobs.Queues[i] = strings.TrimSpace(queue)
+		if cfg.Jobs.Queues[i] == "" {
+			errs = append(errs, errors.New("jobs queues: queue names must not be empty"))
+			break
+		}
+	}
+	if cfg.Jobs.Concurrency, err = getEnvAsInt("JOBS_CONCURRENCY", 10); err != nil {
+		errs = append(errs, fmt.Errorf("jobs concurrency: %w", err))
+	} else if cfg.Jobs.Concurrency < 1 {
+		errs = append(errs, errors.New("jobs concurrency: must be at least 1"))
+	}
+	if cfg.Jobs.PollInterval, err = getEnvAsDuration("JOBS_POLL_INTERVAL", time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("jobs poll interval: %w", err))
+	} else if cfg.Jobs.PollInterval <= 0 {
+		errs = append(errs, errors.New("jobs poll interval: must be positive"))
+	}
+	if cfg.Jobs.VisibilityTimeout, err = getEnvAsDuration("JOBS_VISIBILITY_TIMEOUT", 5*time.Minute); err != nil {
+		errs = append(errs, fmt.Errorf("jobs visibility timeout: %w", err))
+	} else if cfg.Jobs.VisibilityTimeout <= 0 {
+		errs = append(errs, errors.New("jobs visibility timeout: must be positive"))
+	}
+	if cfg.Jobs.MaxAttempts, err = getEnvAsInt("JOBS_MAX_ATTEMPTS", 5); err != nil {
+		errs = append(errs, fmt.Errorf("jobs max attempts: %w", err))
+	} else if cfg.Jobs.MaxAttempts < 1 {
+		errs = append(errs, errors.New("jobs max attempts: must be at least 1"))
+	}
+	if cfg.Jobs.RetryBaseDelay, err = getEnvAsDuration("JOBS_RETRY_BASE_DELAY", 10*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("jobs retry base delay: %w", err))
+	}
+	if cfg.Jobs.RetryMaxDelay, err = getEnvAsDuration("JOBS_RETRY_MAX_DELAY", time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("jobs retry max delay: %w", err))
+	}
+
+	// Scheduler Config
+	if cfg.Scheduler.Enabled, err = getEnvAsBool("SCHEDULER_ENABLED", true); err != nil {
+		errs = append(errs, fmt.Errorf("scheduler enabled: %w", err))
+	}
+	if cfg.Scheduler.Timeout, err = getEnvAsDuration("SCHEDULER_TIMEOUT", 10*time.Minute); err != nil {
+		errs = append(errs, fmt.Errorf("scheduler timeout: %w", err))
+	}
+	if cfg.Scheduler.PurgeDeletedUsers, err = getEnvAsSchedule("SCHEDULE_PURGE_DELETED_USERS", "0 3 * * *"); err != nil {
+		errs = append(errs, fmt.Errorf("schedule purge deleted users: %w", err))
+	}
+	if cfg.Scheduler.DeletedUserRetention, err = getEnvAsDuration("DELETED_USER_RETENTION", 30*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("deleted user retention: %w", err))
+	}
+	if cfg.Scheduler.PruneEmailOutbox, err = getEnvAsSchedule("SCHEDULE_PRUNE_EMAIL_OUTBOX", "30 3 * * *"); err != nil {
+		errs = append(errs, fmt.Errorf("schedule prune email outbox: %w", err))
+	}
+	if cfg.Scheduler.EmailOutboxRetention, err = getEnvAsDuration("EMAIL_OUTBOX_RETENTION", 30*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("email outbox retention: %w", err))
+	}
+	if cfg.Scheduler.PruneRuns, err = getEnvAsSchedule("SCHEDULE_PRUNE_SCHEDULED_RUNS", "45 3 * * *"); err != nil {
+		errs = append(errs, fmt.Errorf("schedule prune scheduled runs: %w", err))
+	}
+	if cfg.Scheduler.RunRetention, err = getEnvAsDuration("SCHEDULED_RUN_RETENTION", 30*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("scheduled run retention: %w", err))
+	}
+	if cfg.Scheduler.RotateJWTKey, err = getEnvAsSchedule("SCHEDULE_ROTATE_JWT_KEY", "off"); err != nil {
+		errs = append(errs, fmt.Errorf("schedule rotate jwt key: %w", err))
+	}
+	if cfg.Scheduler.JWTKeyGrace, err = getEnvAsDuration("JWT_KEY_GRACE", 7*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("jwt key grace: %w", err))
+	}
+	if cfg.Scheduler.CleanupSigningKeys, err = getEnvAsSchedule("SCHEDULE_CLEANUP_SIGNING_KEYS", "0 * * * *"); err != nil {
+		errs = append(errs, fmt.Errorf("schedule cleanup signing keys: %w", err))
+	}
+
+	// Webhooks Config
+	if cfg.Webhooks.Timeout, err = getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("webhook timeout: %w", err))
+	}
+	if cfg.Webhooks.MaxAttempts, err = getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8); err != nil {
+		errs = append(errs, fmt.Errorf("webhook max attempts: %w", err))
+	} else if cfg.Webhooks.MaxAttempts < 1 {
+		errs = append(errs, errors.New("webhook max attempts: must be at least 1"))
+	}
+	if cfg.Webhooks.AllowPrivateNetworks, err = getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false); err != nil {
+		errs = append(errs, fmt.Errorf("webhook allow private networks: %w", err))
+	}
+
+	// Events Config
+	if cfg.Events.RelayInterval, err = getEnvAsDuration("EVENTS_RELAY_INTERVAL", 5*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("events relay interval: %w", err))
+	} else if cfg.Events.RelayInterval <= 0 {
+		errs = append(errs, errors.New("events relay interval: must be positive"))
+	}
+
+	// Realtime Config
+	if cfg.Realtime.HeartbeatInterval, err = getEnvAsDuration("REALTIME_HEARTBEAT_INTERVAL", 25*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("realtime heartbeat interval: %w", err))
+	} else if cfg.Realtime.HeartbeatInterval <= 0 {
+		errs = append(errs, errors.New("realtime heartbeat interval: must be positive"))
+	}
+	if cfg.Realtime.MaxConnections, err = getEnvAsInt("REALTIME_MAX_CONNECTIONS", 5); err != nil {
+		errs = append(errs, fmt.Errorf("realtime max connections: %w", err))
+	} else if cfg.Realtime.MaxConnections < 1 {
+		errs = append(errs, errors.New("realtime max connections: must be at least 1"))
+	}
+	if cfg.Realtime.HistorySize, err = getEnvAsInt("REALTIME_HISTORY_SIZE", 100); err != nil {
+		errs = append(errs, fmt.Errorf("realtime history size: %w", err))
+	} else if cfg.Realtime.HistorySize < 1 {
+		errs = append(errs, errors.New("realtime history size: must be at least 1"))
+	}
+	if cfg.Realtime.HistoryTTL, err = getEnvAsDuration("REALTIME_HISTORY_TTL", 24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("realtime history ttl: %w", err))
+	}
+
+	// Login Alerts Config
+	if cfg.LoginAlerts.Enabled, err = getEnvAsBool("LOGIN_ALERTS_ENABLED", true); err != nil {
+		errs = append(errs, fmt.Errorf("login alerts enabled: %w", err))
+	}
+	cfg.LoginAlerts.GeoIPDatabase = getEnv("GEOIP_DATABASE_PATH", "")
+	if cfg.LoginAlerts.MaxDevices, err = getEnvAsInt("LOGIN_ALERTS_MAX_DEVICES", 10); err != nil {
+		errs = append(errs, fmt.Errorf("login alerts max devices: %w", err))
+	} else if cfg.LoginAlerts.MaxDevices < 1 {
+		errs = append(errs, errors.New("login alerts max devices: must be at least 1"))
+	}
+	cfg.LoginAlerts.RevokeURL = getEnv("LOGIN_ALERTS_REVOKE_URL", "")
+	if cfg.LoginAlerts.RevokeTTL, err = getEnvAsDuration("LOGIN_ALERTS_REVOKE_TTL", 7*24*time.Hour); err != nil {
+		errs = append(errs, fmt.Errorf("login alerts revoke ttl: %w", err))
+	} else if cfg.LoginAlerts.RevokeTTL <= 0 {
+		errs = append(errs, errors.New("login alerts revoke ttl: must be positive"))
+	}
+	if cfg.LoginAlerts.MaxTravelSpeed, err = getEnvAsFloat("LOGIN_ALERTS_MAX_TRAVEL_SPEED", 1000); err != nil {
+		errs = append(errs, fmt.Errorf("login alerts max travel speed: %w", err))
+	} else if cfg.LoginAlerts.MaxTravelSpeed <= 0 {
+		errs = append(errs, errors.New("login alerts max travel speed: must be positive"))
+	}
+	if cfg.LoginAlerts.StepUp, err = getEnvAsBool("LOGIN_ALERTS_STEP_UP", false); err != nil {
+		errs = append(errs, fmt.Errorf("login alerts step up: %w", err))
 	}
 
 	// Logger Config
 	cfg.Logger.Level = getEnv("LOG_LEVEL", "info") // Can have a default level
 
+	// Health Config
+	if cfg.Health.Timeout, err = getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("health check timeout: %w", err))
+	}
+	if cfg.Health.CacheTTL, err = getEnvAsDuration("HEALTH_CACHE_TTL", 5*time.Second); err != nil {
+		errs = append(errs, fmt.Errorf("health cache ttl: %w", err))
+	}
+	if cfg.Health.CheckSMTP, err = getEnvAsBool("HEALTH_CHECK_SMTP", false); err != nil {
+		errs = append(errs, fmt.Errorf("health check smtp: %w", err))
+	}
+
+	// Metrics Config
+	if cfg.Metrics.Enabled, err = getEnvAsBool("METRICS_ENABLED", true); err != nil {
+		errs = append(errs, fmt.Errorf("metrics enabled: %w", err))
+	}
+	if cfg.Metrics.Port, err = getEnvAsInt("METRICS_PORT", 0); err != nil {
+		errs = append(errs, fmt.Errorf("metrics port: %w", err))
+	}
+
+	// Tracing Config
+	if cfg.Tracing.Enabled, err = getEnvAsBool("TRACING_ENABLED", false); err != nil {
+		errs = append(errs, fmt.Errorf("tracing enabled: %w", err))
+	}
+	cfg.Tracing.ServiceName = getEnv("OTEL_SERVICE_NAME", "go-fiber-boilerplate")
+	if cfg.Tracing.SampleRatio, err = getEnvAsFloat("TRACING_SAMPLE_RATIO", 1); err != nil {
+		errs = append(errs, fmt.Errorf("tracing sample ratio: %w", err))
+	}
+
 	// App Config
+	cfg.App.Name = getEnv("APP_NAME", "go-fiber-boilerplate")
 	cfg.App.Environment = getEnv("ENV", "development") // Can have a default environment
+	cfg.App.ErrorFormat = getEnv("ERROR_FORMAT", "json")
+	if cfg.App.ErrorFormat != "json" && cfg.App.ErrorFormat != "problem" {
+		errs = append(errs, fmt.Errorf("error format: must be \"json\" or \"problem\", got %q", cfg.App.ErrorFormat))
+	}
+	if cfg.App.ValidateRequests, err = getEnvAsBool("OPENAPI_VALIDATE_REQUESTS", false); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.App.ValidateResponses, err = getEnvAsBool("OPENAPI_VALIDATE_RESPONSES", false); err != nil {
+		errs = append(errs, err)
+	}
+	// The console channel logs plaintext codes, which would let anyone
+	// reading the logs sign in
+	if slices.Contains(cfg.OTP.Channels, "console") && cfg.App.Environment != "development" {
+		errs = append(errs, fmt.Errorf("otp channels: console is only allowed with ENV=development, got %q", cfg.App.Environment))
+	}
 
 	if len(errs) > 0 {
 		return nil, errors.Join(errs...)
@@ -157,3 +677,64 @@ func getEnvAsIntRequired(key string) (int, error) {
 	}
 	return intValue, nil
 }
+
+func getEnvAsInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer value for environment variable %s: %w", key, err)
+	}
+	return intValue, nil
+}
+
+func getEnvAsFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float value for environment variable %s: %w", key, err)
+	}
+	return floatValue, nil
+}
+
+func getEnvAsBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value for environment variable %s: %w", key, err)
+	}
+	return boolValue, nil
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value for environment variable %s: %w", key, err)
+	}
+	return duration, nil
+}
+
+// getEnvAsSchedule reads a cron expression; "off" disables the job and is
+// returned as an empty schedule
+func getEnvAsSchedule(key, defaultValue string) (string, error) {
+	value := getEnv(key, defaultValue)
+	if value == "off" {
+		return "", nil
+	}
+	if _, err := cron.ParseStandard(value); err != nil {
+		return "", fmt.Errorf("invalid cron schedule for environment variable %s: %w", key, err)
+	}
+	return value, nil
+}
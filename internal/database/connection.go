@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"time"
 
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
@@ -45,6 +46,15 @@ func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// Ping checks that the database is reachable
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // Close closes the database connection
 func Close(db *gorm.DB) error {
 	sqlDB, err := db.DB()
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed fixtures/*.json
+var fixturesFS embed.FS
+
+// Fixtures is the seed data loaded by the `seed` command
+type Fixtures struct {
+	Users []UserFixture `json:"users"`
+}
+
+// UserFixture describes a user to create while seeding
+type UserFixture struct {
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+}
+
+// LoadFixtures reads fixtures from path, or the embedded defaults when path is empty
+func LoadFixtures(path string) (*Fixtures, error) {
+	var data []byte
+	var err error
+	if path == "" {
+		data, err = fixturesFS.ReadFile("fixtures/users.json")
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid fixtures file: %w", err)
+	}
+	return &fixtures, nil
+}
@@ -0,0 +1,119 @@
+package email
+
+import (
+	"fmt"
+	"time"
+)
+
+// Template names of the transactional emails
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+	TemplateOTP           = "otp"
+	TemplateNewLogin      = "new_login"
+	TemplateInvitation    = "invitation"
+	// TemplateWelcome and TemplatePasswordChanged are sent by notifications
+	TemplateWelcome         = "welcome"
+	TemplatePasswordChanged = "password_changed"
+)
+
+// VerifyEmailData is the data of the verify email template
+type VerifyEmailData struct {
+	Name      string
+	VerifyURL string
+	ExpiresIn time.Duration
+}
+
+// ResetPasswordData is the data of the reset password template
+type ResetPasswordData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn time.Duration
+}
+
+// OTPData is the data of the OTP template
+type OTPData struct {
+	Code      string
+	ExpiresIn time.Duration
+}
+
+// NewLoginData is the data of the new sign-in alert template
+type NewLoginData struct {
+	Name      string
+	Time      time.Time
+	IP        string
+	UserAgent string
+	Location  string
+	// RevokeURL signs out every session when the sign-in wasn't the user
+	RevokeURL string
+	// Suspicious is set when the sign-in was too far from the previous one
+	Suspicious bool
+}
+
+// InvitationData is the data of the invitation template
+type InvitationData struct {
+	InviterName string
+	AcceptURL   string
+	ExpiresIn   time.Duration
+}
+
+// WelcomeData is the data of the welcome template
+type WelcomeData struct {
+	Name string
+}
+
+// PasswordChangedData is the data of the password changed template
+type PasswordChangedData struct {
+	Name string
+	Time time.Time
+}
+
+// NewData returns a pointer to empty data of the template name, for decoding
+// data that was serialized, e.g. in a job payload
+func NewData(name string) (interface{}, error) {
+	switch name {
+	case TemplateVerifyEmail:
+		return &VerifyEmailData{}, nil
+	case TemplateResetPassword:
+		return &ResetPasswordData{}, nil
+	case TemplateOTP:
+		return &OTPData{}, nil
+	case TemplateNewLogin:
+		return &NewLoginData{}, nil
+	case TemplateInvitation:
+		return &InvitationData{}, nil
+	case TemplateWelcome:
+		return &WelcomeData{}, nil
+	case TemplatePasswordChanged:
+		return &PasswordChangedData{}, nil
+	}
+	return nil, fmt.Errorf("email: unknown template %q", name)
+}
+
+// SampleData returns example data for previewing the template name
+func SampleData(name string) (interface{}, error) {
+	switch name {
+	case TemplateVerifyEmail:
+		return VerifyEmailData{Name: "Ada", VerifyURL: "https://example.com/verify?token=sample", ExpiresIn: 24 * time.Hour}, nil
+	case TemplateResetPassword:
+		return ResetPasswordData{Name: "Ada", ResetURL: "https://example.com/reset?token=sample", ExpiresIn: time.Hour}, nil
+	case TemplateOTP:
+		return OTPData{Code: "123456", ExpiresIn: 10 * time.Minute}, nil
+	case TemplateNewLogin:
+		return NewLoginData{
+			Name:      "Ada",
+			Time:      time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC),
+			IP:        "203.0.113.7",
+			UserAgent: "Firefox on Linux",
+			Location:  "Lisbon, Portugal",
+			RevokeURL: "https://example.com/sessions/revoke?token=sample",
+		}, nil
+	case TemplateInvitation:
+		return InvitationData{InviterName: "Grace", AcceptURL: "https://example.com/invite?token=sample", ExpiresIn: 7 * 24 * time.Hour}, nil
+	case TemplateWelcome:
+		return WelcomeData{Name: "Ada"}, nil
+	case TemplatePasswordChanged:
+		return PasswordChangedData{Name: "Ada", Time: time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)}, nil
+	}
+	return nil, fmt.Errorf("email: no sample data for template %q", name)
+}
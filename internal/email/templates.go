@@ -0,0 +1,206 @@
+// Package email renders the transactional emails from HTML and text
+// templates. Templates are embedded, can be overridden from a directory and
+// have per-locale variants.
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+)
+
+//go:embed templates/*.html templates/*.txt
+var embedded embed.FS
+
+// Template files: <name>.html holds the "subject" and "content" blocks, which
+// layout.html wraps; <name>.txt and layout.txt optionally replace the text
+// alternative generated from the HTML. <name>.<locale>.html is preferred for
+// that locale.
+const (
+	layoutName = "layout"
+	htmlExt    = ".html"
+	textExt    = ".txt"
+)
+
+// Rendered is a rendered email ready to send
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Renderer renders email templates, caching parsed templates per locale
+type Renderer struct {
+	appName string
+	sources []fs.FS
+
+	mu    sync.Mutex
+	cache map[string]*parsed
+}
+
+type parsed struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// NewRenderer creates a renderer. Files in overrideDir, if set, take
+// precedence over the embedded templates with the same name.
+func NewRenderer(appName, overrideDir string) (*Renderer, error) {
+	defaults, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+	sources := []fs.FS{defaults}
+	if overrideDir != "" {
+		info, err := os.Stat(overrideDir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, errors.New("email: template override path is not a directory: " + overrideDir)
+		}
+		sources = append([]fs.FS{os.DirFS(overrideDir)}, sources...)
+	}
+	return &Renderer{
+		appName: appName,
+		sources: sources,
+		cache:   map[string]*parsed{},
+	}, nil
+}
+
+// Render renders the template name in locale with data
+func (r *Renderer) Render(locale, name string, data interface{}) (*Rendered, error) {
+	tmpl, err := r.load(locale, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, html bytes.Buffer
+	if err := tmpl.html.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, layoutName, data); err != nil {
+		return nil, err
+	}
+	rendered := &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+	}
+
+	if tmpl.text != nil {
+		var text bytes.Buffer
+		if err := tmpl.text.ExecuteTemplate(&text, layoutName, data); err != nil {
+			return nil, err
+		}
+		rendered.Text = strings.TrimSpace(text.String()) + "\n"
+	} else {
+		rendered.Text = HTMLToText(rendered.HTML)
+	}
+	return rendered, nil
+}
+
+// load parses the templates for name in locale, or returns them from the cache
+func (r *Renderer) load(locale, name string) (*parsed, error) {
+	key := locale + "/" + name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tmpl, ok := r.cache[key]; ok {
+		return tmpl, nil
+	}
+
+	funcs := r.funcs(locale)
+	layout, _, err := r.read(locale, layoutName+htmlExt)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSource, err := r.read(locale, name+htmlExt)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(name).Funcs(funcs).Parse(layout)
+	if err == nil {
+		_, err = html.Parse(page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &parsed{html: html}
+
+	// The text templates are optional, and ignored when only the HTML was
+	// overridden so the text alternative can't contradict it
+	if textPage, textSource, err := r.read(locale, name+textExt); err == nil && textSource <= pageSource {
+		textLayout, _, err := r.read(locale, layoutName+textExt)
+		if err != nil {
+			return nil, err
+		}
+		text, err := texttemplate.New(name).Funcs(texttemplate.FuncMap(funcs)).Parse(textLayout)
+		if err == nil {
+			_, err = text.Parse(textPage)
+		}
+		if err != nil {
+			return nil, err
+		}
+		tmpl.text = text
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	r.cache[key] = tmpl
+	return tmpl, nil
+}
+
+// read returns the locale variant of file, falling back to the default file,
+// and the index of the source it was found in (0 is the most specific)
+func (r *Renderer) read(locale, file string) (string, int, error) {
+	ext := file[strings.LastIndex(file, "."):]
+	candidates := []string{strings.TrimSuffix(file, ext) + "." + locale + ext, file}
+	for _, candidate := range candidates {
+		for i, source := range r.sources {
+			data, err := fs.ReadFile(source, candidate)
+			if err == nil {
+				return string(data), i, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", 0, err
+			}
+		}
+	}
+	return "", 0, &fs.PathError{Op: "open", Path: file, Err: fs.ErrNotExist}
+}
+
+// funcs returns the helpers available to templates
+func (r *Renderer) funcs(locale string) htmltemplate.FuncMap {
+	return htmltemplate.FuncMap{
+		"appName": func() string { return r.appName },
+		"locale":  func() string { return locale },
+		"t": func(key string) string {
+			return i18n.Translate(locale, key, i18n.Params{"app": r.appName})
+		},
+		"minutes": func(d time.Duration) int { return int(d.Round(time.Minute) / time.Minute) },
+		"hours":   func(d time.Duration) int { return int(d.Round(time.Hour) / time.Hour) },
+		"days":    func(d time.Duration) int { return int(d.Round(24*time.Hour) / (24 * time.Hour)) },
+	}
+}
+
+// Templates lists the names of the embedded email templates
+func Templates() []string {
+	var names []string
+	entries, _ := fs.ReadDir(embedded, "templates")
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), htmlExt)
+		if name == entry.Name() || name == layoutName || strings.Contains(name, ".") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,80 @@
+package email
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// HTMLToText derives a plain text alternative from an HTML email. Block
+// elements become line breaks and links are followed by their URL.
+func HTMLToText(document string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+	var out strings.Builder
+	var hrefs []string
+	skip := 0
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			text := blankLines.ReplaceAllString(out.String(), "\n\n")
+			return strings.TrimSpace(text) + "\n"
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := strings.Join(strings.Fields(string(tokenizer.Text())), " ")
+			if text == "" {
+				continue
+			}
+			if current := out.String(); current != "" && !strings.HasSuffix(current, "\n") && !strings.HasSuffix(current, " ") {
+				out.WriteByte(' ')
+			}
+			out.WriteString(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "head", "style", "script", "title":
+				skip++
+			case "br":
+				out.WriteByte('\n')
+			case "li":
+				out.WriteString("\n- ")
+			case "a":
+				hrefs = append(hrefs, attribute(token, "href"))
+			case "p", "div", "tr", "table", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6":
+				out.WriteString("\n\n")
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "head", "style", "script", "title":
+				skip--
+			case "a":
+				if len(hrefs) == 0 {
+					continue
+				}
+				href := hrefs[len(hrefs)-1]
+				hrefs = hrefs[:len(hrefs)-1]
+				if href != "" && !strings.HasSuffix(out.String(), href) {
+					out.WriteString(" (" + href + ")")
+				}
+			case "p", "div", "tr", "table", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6":
+				out.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+// attribute returns the value of the named attribute of token
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
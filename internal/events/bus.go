@@ -0,0 +1,334 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// relayLease hides events claimed for publishing from other instances
+	relayLease = time.Minute
+	// relayGrace leaves fresh events to the transaction that wrote them; the
+	// relay only picks up events whose publisher died after the commit
+	relayGrace     = 30 * time.Second
+	relayBatchSize = 100
+	// retryBaseDelay and retryMaxDelay bound the backoff of events whose
+	// subscribers failed, doubled per failed publish
+	retryBaseDelay = 10 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+// Handler reacts to an event. An event published from the outbox is
+// delivered to synchronous handlers again when one of them fails, so ctx
+// carries the event's ID, the same on every delivery, for handlers to skip
+// events they already handled.
+type Handler func(ctx context.Context, event Event) error
+
+type idContextKey struct{}
+
+// IDFromContext returns the ID of the event being handled, or uuid.Nil
+// outside a handler
+func IDFromContext(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(idContextKey{}).(uuid.UUID)
+	return id
+}
+
+// Bus delivers events to subscribers. Synchronous subscribers run before
+// Publish returns and, for events emitted in a transaction, before the
+// event leaves the outbox, so they are retried if they fail or the process
+// dies. Async subscribers run in their own goroutine and are best effort:
+// they are not run again when an event is retried.
+type Bus struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	// outboxOnly leaves emitted events for another process's relay
+	outboxOnly bool
+
+	mu    sync.RWMutex
+	sync  map[string][]Handler
+	async map[string][]Handler
+
+	// running counts the relay and async handlers
+	running sync.WaitGroup
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewBus creates a bus that keeps its outbox in db
+func NewBus(db *gorm.DB, logger *zap.Logger) *Bus {
+	return &Bus{
+		db:     db,
+		logger: logger,
+		sync:   map[string][]Handler{},
+		async:  map[string][]Handler{},
+		stop:   make(chan struct{}),
+	}
+}
+
+// NewOutboxBus creates a bus that only writes emitted events to the outbox in
+// db, for processes that can't reach the subscribers. The relay of a running
+// server publishes them.
+func NewOutboxBus(db *gorm.DB, logger *zap.Logger) *Bus {
+	bus := NewBus(db, logger)
+	bus.outboxOnly = true
+	return bus
+}
+
+// Subscribe runs handler for every event named name, before Publish returns
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync[name] = append(b.sync[name], handler)
+}
+
+// SubscribeAsync runs handler for every event named name in the background
+func (b *Bus) SubscribeAsync(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[name] = append(b.async[name], handler)
+}
+
+// Publish delivers event to its subscribers now. Use Transaction for events
+// that describe changes being written to the database. Publishing on a nil
+// bus does nothing.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+	_ = b.publish(ctx, uuid.New(), event, true)
+}
+
+// publish runs the subscribers of event, the async ones only if async is
+// set, and returns the errors of the synchronous ones
+func (b *Bus) publish(ctx context.Context, id uuid.UUID, event Event, async bool) error {
+	ctx = context.WithValue(ctx, idContextKey{}, id)
+	b.mu.RLock()
+	syncHandlers := b.sync[event.EventName()]
+	var asyncHandlers []Handler
+	if async {
+		asyncHandlers = b.async[event.EventName()]
+	}
+	b.mu.RUnlock()
+	metrics.EventsPublishedTotal.WithLabelValues(event.EventName()).Inc()
+
+	var errs []error
+	for _, handler := range syncHandlers {
+		if err := b.call(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Keep the request's values, such as its logger, but not its deadline
+	asyncCtx := context.WithoutCancel(ctx)
+	for _, handler := range asyncHandlers {
+		b.running.Add(1)
+		go func(handler Handler) {
+			defer b.running.Done()
+			_ = b.call(asyncCtx, handler, event)
+		}(handler)
+	}
+	return errors.Join(errs...)
+}
+
+// Transaction runs fn in a database transaction. Events passed to emit are
+// written to the outbox in that transaction and published once it commits;
+// if it rolls back they are discarded.
+func (b *Bus) Transaction(ctx context.Context, fn func(tx *gorm.DB, emit func(Event) error) error) error {
+	var emitted []models.OutboxEvent
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		emit := func(event Event) error {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			row := models.OutboxEvent{Name: event.EventName(), Payload: payload}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+			emitted = append(emitted, row)
+			return nil
+		}
+		return fn(tx, emit)
+	})
+	if err != nil || len(emitted) == 0 || b.outboxOnly {
+		return err
+	}
+
+	ids := make([]uuid.UUID, len(emitted))
+	for i, row := range emitted {
+		ids[i] = row.ID
+	}
+	rows, err := b.claim(ctx, "id IN ?", ids)
+	if err != nil {
+		// The relay publishes them later
+		b.log(ctx).Error("Failed to claim emitted events", zap.Error(err))
+		return nil
+	}
+	b.publishRows(ctx, rows)
+	return nil
+}
+
+// Start publishes events left in the outbox by publishers that died between
+// commit and publish, polling every interval until Stop is called
+func (b *Bus) Start(interval time.Duration) {
+	b.running.Add(1)
+	go func() {
+		defer b.running.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+			}
+			for b.relay() == relayBatchSize {
+				if b.stopped() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the relay and waits for running async handlers, or for ctx to expire
+func (b *Bus) Stop(ctx context.Context) error {
+	b.once.Do(func() { close(b.stop) })
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relay publishes one batch of stale outbox events and returns its size
+func (b *Bus) relay() int {
+	ctx := context.Background()
+	rows, err := b.claim(ctx, "created_at < ?", time.Now().Add(-relayGrace))
+	if err != nil {
+		b.logger.Error("Failed to claim outbox events", zap.Error(err))
+		return 0
+	}
+	if len(rows) > 0 {
+		b.logger.Info("Publishing outbox events", zap.Int("count", len(rows)))
+	}
+	b.publishRows(ctx, rows)
+	return len(rows)
+}
+
+// claim leases up to relayBatchSize unclaimed outbox events matching the
+// condition, oldest first
+func (b *Bus) claim(ctx context.Context, condition string, arg interface{}) ([]models.OutboxEvent, error) {
+	now := time.Now()
+	var rows []models.OutboxEvent
+	err := b.db.WithContext(ctx).Raw(`
+		UPDATE event_outbox SET locked_until = ?
+		WHERE id IN (
+			SELECT id FROM event_outbox
+			WHERE `+condition+` AND (locked_until IS NULL OR locked_until < ?)
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(relayLease), arg, now, relayBatchSize,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+// publishRows publishes claimed outbox events and deletes the ones whose
+// subscribers all succeeded. Failed events keep their lease, pushed out with
+// backoff, so the relay retries them later; async subscribers only see an
+// event's first attempt.
+func (b *Bus) publishRows(ctx context.Context, rows []models.OutboxEvent) {
+	for _, row := range rows {
+		// An event this version doesn't know may be published by a newer one
+		event, err := decode(row.Name, row.Payload)
+		if err == nil {
+			err = b.publish(ctx, row.ID, event, row.Attempts == 0)
+		}
+		if err != nil {
+			b.retryLater(ctx, row, err)
+			continue
+		}
+		if err := b.db.WithContext(ctx).Delete(&models.OutboxEvent{}, "id = ?", row.ID).Error; err != nil {
+			b.log(ctx).Error("Failed to delete published event", zap.String("event_id", row.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// retryLater records a failed publish of row and hides it from the relay
+// until its backoff has passed
+func (b *Bus) retryLater(ctx context.Context, row models.OutboxEvent, publishErr error) {
+	attempts := row.Attempts + 1
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, retryMaxDelay)
+	b.log(ctx).Warn("Event publish failed, will retry",
+		zap.String("event_id", row.ID.String()),
+		zap.String("event", row.Name),
+		zap.Int("attempts", attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(publishErr),
+	)
+	err := b.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", row.ID).Updates(map[string]interface{}{
+		"attempts":     attempts,
+		"last_error":   publishErr.Error(),
+		"locked_until": time.Now().Add(delay),
+	}).Error
+	if err != nil {
+		// The lease still runs out, so the relay retries sooner
+		b.log(ctx).Error("Failed to record event publish failure", zap.String("event_id", row.ID.String()), zap.Error(err))
+	}
+}
+
+// call runs handler, logging and returning its error or panic
+func (b *Bus) call(ctx context.Context, handler Handler, event Event) (err error) {
+	logger := b.log(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.EventHandlerFailuresTotal.WithLabelValues(event.EventName()).Inc()
+			logger.Error("Event handler panicked", zap.String("event", event.EventName()), zap.Any("panic", r))
+			err = fmt.Errorf("event handler panicked: %v", r)
+		}
+	}()
+	if err := handler(ctx, event); err != nil {
+		metrics.EventHandlerFailuresTotal.WithLabelValues(event.EventName()).Inc()
+		logger.Error("Event handler failed", zap.String("event", event.EventName()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// log returns the request's logger carried by ctx, or the bus logger on the
+// relay and other paths outside a request
+func (b *Bus) log(ctx context.Context) *zap.Logger {
+	return utils.LoggerFromContextOr(ctx, b.logger)
+}
+
+func (b *Bus) stopped() bool {
+	select {
+	case <-b.stop:
+		return true
+	default:
+		return false
+	}
+}
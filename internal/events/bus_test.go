@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestPublishRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		async     bool
+		wantAsync bool
+	}{
+		{name: "first attempt", async: true, wantAsync: true},
+		{name: "retry", async: false, wantAsync: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus(nil, zap.NewNop())
+			var seen []uuid.UUID
+			bus.Subscribe(NameUserDeleted, func(ctx context.Context, event Event) error {
+				seen = append(seen, IDFromContext(ctx))
+				return nil
+			})
+			bus.Subscribe(NameUserDeleted, func(ctx context.Context, event Event) error {
+				return errors.New("unavailable")
+			})
+			ran := make(chan uuid.UUID, 1)
+			bus.SubscribeAsync(NameUserDeleted, func(ctx context.Context, event Event) error {
+				ran <- IDFromContext(ctx)
+				return nil
+			})
+
+			id := uuid.New()
+			err := bus.publish(context.Background(), id, UserDeleted{UserID: uuid.New()}, tt.async)
+			if err == nil {
+				t.Fatal("the failing handler's error was not returned")
+			}
+			if len(seen) != 1 || seen[0] != id {
+				t.Errorf("handler saw IDs %v, want [%s]", seen, id)
+			}
+			if err := bus.Stop(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case got := <-ran:
+				if !tt.wantAsync {
+					t.Error("async handler ran on a retry")
+				} else if got != id {
+					t.Errorf("async handler saw ID %s, want %s", got, id)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantAsync {
+					t.Error("async handler didn't run on the first attempt")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package events is an in-process bus for domain events. Services publish
+// typed events and subscribers react to them, so side effects such as
+// webhooks live outside the service that raised the event.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+)
+
+// Event is a domain event. Events are stored as JSON in the outbox, so their
+// fields must survive a round trip.
+type Event interface {
+	EventName() string
+}
+
+// Event names
+const (
+	NameUserRegistered  = "user.registered"
+	NameUserUpdated     = "user.updated"
+	NameUserLoggedIn    = "user.logged_in"
+	NamePasswordChanged = "user.password_changed"
+	NameUserDeleted     = "user.deleted"
+	NameSessionsRevoked = "user.sessions_revoked"
+	// NameNotificationCreated is only pushed to the user's open streams
+	NameNotificationCreated = "notification.created"
+)
+
+// Login methods of UserLoggedIn
+const (
+	LoginMethodPassword = "password"
+	LoginMethodOTP      = "otp"
+)
+
+// UserRegistered is raised when an account is created
+type UserRegistered struct {
+	User models.UserResponse `json:"user"`
+}
+
+// UserUpdated is raised when a user's profile changes
+type UserUpdated struct {
+	User models.UserResponse `json:"user"`
+}
+
+// UserLoggedIn is raised when a user signs in
+type UserLoggedIn struct {
+	User models.UserResponse `json:"user"`
+	// Method is how the user signed in, one of the LoginMethod constants
+	Method string `json:"method"`
+}
+
+// PasswordChanged is raised when a user's password is set
+type PasswordChanged struct {
+	User models.UserResponse `json:"user"`
+}
+
+// UserDeleted is raised when a user is deleted
+type UserDeleted struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// SessionsRevoked is raised when a user signs out everywhere
+type SessionsRevoked struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// NotificationCreated is pushed to a user's streams when they are notified
+type NotificationCreated struct {
+	Notification models.Notification `json:"notification"`
+}
+
+func (UserRegistered) EventName() string      { return NameUserRegistered }
+func (UserUpdated) EventName() string         { return NameUserUpdated }
+func (UserLoggedIn) EventName() string        { return NameUserLoggedIn }
+func (PasswordChanged) EventName() string     { return NamePasswordChanged }
+func (UserDeleted) EventName() string         { return NameUserDeleted }
+func (SessionsRevoked) EventName() string     { return NameSessionsRevoked }
+func (NotificationCreated) EventName() string { return NameNotificationCreated }
+
+// types maps event names to their Go types, for decoding the outbox
+var types = map[string]reflect.Type{}
+
+func init() {
+	for _, event := range []Event{UserRegistered{}, UserUpdated{}, UserLoggedIn{}, PasswordChanged{}, UserDeleted{}, SessionsRevoked{}, NotificationCreated{}} {
+		types[event.EventName()] = reflect.TypeOf(event)
+	}
+}
+
+// decode rebuilds an event stored in the outbox
+func decode(name string, payload []byte) (Event, error) {
+	t, ok := types[name]
+	if !ok {
+		return nil, fmt.Errorf("events: unknown event %q", name)
+	}
+	value := reflect.New(t)
+	if err := json.Unmarshal(payload, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface().(Event), nil
+}
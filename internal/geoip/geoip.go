@@ -0,0 +1,115 @@
+// Package geoip locates IP addresses with a local MaxMind-format database,
+// such as GeoLite2 City or DB-IP City Lite. Country databases work too, but
+// only locate addresses to a country, without coordinates.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// earthRadiusKm is the mean radius used for distances between locations
+const earthRadiusKm = 6371
+
+// Location is the approximate place of an IP address
+type Location struct {
+	City    string
+	Country string
+	// Latitude and Longitude are only meaningful when HasCoordinates is set
+	Latitude       float64
+	Longitude      float64
+	HasCoordinates bool
+}
+
+// String returns "City, Country", or whichever of the two is known
+func (l Location) String() string {
+	switch {
+	case l.City != "" && l.Country != "":
+		return l.City + ", " + l.Country
+	case l.City != "":
+		return l.City
+	default:
+		return l.Country
+	}
+}
+
+// DistanceKm returns the great-circle distance between two coordinates
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Locator looks up IP addresses. A nil Locator locates nothing, so callers
+// don't need to check whether a database was configured.
+type Locator struct {
+	reader *geoip2.Reader
+}
+
+// Open loads the database at path
+func Open(path string) (*Locator, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %w", err)
+	}
+	// ASN and other databases without locations can't answer city lookups
+	var invalid geoip2.InvalidMethodError
+	if _, err := reader.City(net.IPv4zero); errors.As(err, &invalid) {
+		reader.Close()
+		return nil, fmt.Errorf("geoip: %s is a %s database, not a city or country database", path, invalid.DatabaseType)
+	}
+	return &Locator{reader: reader}, nil
+}
+
+// Lookup returns the location of ip with names in locale, falling back to
+// English. It reports false for private, invalid and unknown addresses.
+func (l *Locator) Lookup(ip, locale string) (Location, bool) {
+	if l == nil {
+		return Location{}, false
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil || addr.IsPrivate() || addr.IsLoopback() || addr.IsUnspecified() {
+		return Location{}, false
+	}
+	record, err := l.reader.City(addr)
+	if err != nil {
+		return Location{}, false
+	}
+	loc := Location{
+		City:    name(record.City.Names, locale),
+		Country: name(record.Country.Names, locale),
+	}
+	// Records without coordinates have no accuracy radius
+	if record.Location.AccuracyRadius > 0 {
+		loc.Latitude = record.Location.Latitude
+		loc.Longitude = record.Location.Longitude
+		loc.HasCoordinates = true
+	}
+	if loc.City == "" && loc.Country == "" && !loc.HasCoordinates {
+		return Location{}, false
+	}
+	return loc, true
+}
+
+// Close releases the database
+func (l *Locator) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.reader.Close()
+}
+
+// name returns the name in locale, or in English
+func name(names map[string]string, locale string) string {
+	if n, ok := names[locale]; ok {
+		return n
+	}
+	return names["en"]
+}
@@ -0,0 +1,208 @@
+// Package i18n provides the embedded message catalogs, locale negotiation and
+// validator message translations.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// DefaultLocale is used when no supported locale matches the request
+const DefaultLocale = "en"
+
+// Params holds the values substituted for {name} placeholders in a message
+type Params map[string]string
+
+type localeContextKey struct{}
+
+// supportedLocales lists the locales with a catalog, default first
+var supportedLocales = []struct {
+	tag          language.Tag
+	translator   locales.Translator
+	translations func(v *validator.Validate, trans ut.Translator) error
+}{
+	{language.English, en.New(), en_translations.RegisterDefaultTranslations},
+	{language.Spanish, es.New(), es_translations.RegisterDefaultTranslations},
+}
+
+var (
+	catalogs  = map[string]map[string]string{}
+	matcher   language.Matcher
+	universal *ut.UniversalTranslator
+)
+
+func init() {
+	tags := make([]language.Tag, 0, len(supportedLocales))
+	translators := make([]locales.Translator, 0, len(supportedLocales))
+	for _, locale := range supportedLocales {
+		name := locale.tag.String()
+		data, err := localesFS.ReadFile(path.Join("locales", name+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", name, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", name, err))
+		}
+		catalogs[name] = catalog
+		tags = append(tags, locale.tag)
+		translators = append(translators, locale.translator)
+	}
+	matcher = language.NewMatcher(tags)
+	universal = ut.New(translators[0], translators...)
+}
+
+// Supported returns the supported locale names
+func Supported() []string {
+	names := make([]string, 0, len(supportedLocales))
+	for _, locale := range supportedLocales {
+		names = append(names, locale.tag.String())
+	}
+	return names
+}
+
+// IsSupported reports whether locale has a catalog
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Match returns the best supported locale for an Accept-Language header value
+func Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return supportedLocales[index].tag.String()
+}
+
+// WithLocale returns a copy of ctx carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// FromContext returns the locale in ctx, or DefaultLocale
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeContextKey{}).(string); ok && IsSupported(locale) {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates key into the locale in ctx
+func T(ctx context.Context, key string, params Params) string {
+	return Translate(FromContext(ctx), key, params)
+}
+
+// Translate returns the message for key in locale, falling back to the
+// default locale and then to the key itself
+func Translate(locale, key string, params Params) string {
+	message, ok := lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return interpolate(message, params)
+}
+
+// lookup finds key in the locale's catalog or the default catalog
+func lookup(locale, key string) (string, bool) {
+	if message, ok := catalogs[locale][key]; ok {
+		return message, true
+	}
+	message, ok := catalogs[DefaultLocale][key]
+	return message, ok
+}
+
+// interpolate replaces {name} placeholders with params
+func interpolate(message string, params Params) string {
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
+
+// TranslateError localizes the message of an error with the given code. Only
+// the generic message for the code is translated; more specific messages are
+// returned unchanged.
+func TranslateError(ctx context.Context, code, message string) string {
+	key := "error." + code
+	if generic, ok := catalogs[DefaultLocale][key]; !ok || generic != message {
+		return message
+	}
+	return T(ctx, key, nil)
+}
+
+// RegisterValidatorTranslations registers the validator's built-in messages
+// for every supported locale
+func RegisterValidatorTranslations(v *validator.Validate) error {
+	for _, locale := range supportedLocales {
+		trans, _ := universal.GetTranslator(locale.tag.String())
+		if err := locale.translations(v, trans); err != nil {
+			return fmt.Errorf("i18n: failed to register %s validator translations: %w", locale.tag, err)
+		}
+	}
+	return nil
+}
+
+// FieldErrorMessage translates a validation failure into the locale in ctx.
+// Catalog entries take precedence over the validator's built-in translations.
+func FieldErrorMessage(ctx context.Context, fe validator.FieldError) string {
+	locale := FromContext(ctx)
+	params := Params{"field": fe.Field(), "rule": fe.Tag(), "param": fe.Param()}
+	if message, ok := ruleMessage(locale, fe.Tag(), params, fe.Kind() == reflect.String); ok {
+		return message
+	}
+
+	trans, _ := universal.GetTranslator(locale)
+	if message := fe.Translate(trans); message != fe.Error() {
+		return message
+	}
+	return Translate(locale, "validation.default", params)
+}
+
+// RuleMessage translates a failed validation rule on field into the locale
+// in ctx. isString selects the length wording of min and max.
+func RuleMessage(ctx context.Context, rule, field, param string, isString bool) string {
+	locale := FromContext(ctx)
+	params := Params{"field": field, "rule": rule, "param": param}
+	if message, ok := ruleMessage(locale, rule, params, isString); ok {
+		return message
+	}
+	return Translate(locale, "validation.default", params)
+}
+
+// ruleMessage looks up validation.<rule>, preferring validation.<rule>.string
+// for string values
+func ruleMessage(locale, rule string, params Params, isString bool) (string, bool) {
+	key := "validation." + rule
+	if isString {
+		if message, ok := lookup(locale, key+".string"); ok {
+			return interpolate(message, params), true
+		}
+	}
+	if message, ok := lookup(locale, key); ok {
+		return interpolate(message, params), true
+	}
+	return "", false
+}
@@ -0,0 +1,234 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrJobNotFound is returned for unknown job IDs
+var ErrJobNotFound = errors.New("jobs: job not found")
+
+// ErrJobNotFailed is returned when retrying a job that isn't dead
+var ErrJobNotFailed = errors.New("jobs: job has not failed")
+
+// ErrUniqueKeyTaken is returned when retrying a dead job whose unique key
+// another job holds
+var ErrUniqueKeyTaken = errors.New("jobs: another job holds the unique key")
+
+// Options controls how a job is enqueued
+type Options struct {
+	// Queue defaults to DefaultQueue
+	Queue string
+	// Delay postpones the first run
+	Delay time.Duration
+	// MaxAttempts overrides the client's default
+	MaxAttempts int
+	// UniqueKey, when set, skips the enqueue while another job of the queue
+	// holds the same key, i.e. until it succeeds or dies
+	UniqueKey string
+	// UniqueFor bounds how long the key is held, in case the job is lost (default 24h)
+	UniqueFor time.Duration
+}
+
+// Client enqueues and inspects jobs
+type Client struct {
+	rdb         *redis.Client
+	maxAttempts int
+}
+
+// NewClient creates a client. Jobs get maxAttempts runs unless their
+// options say otherwise.
+func NewClient(rdb *redis.Client, maxAttempts int) *Client {
+	return &Client{rdb: rdb, maxAttempts: maxAttempts}
+}
+
+// enqueueScript stores the job and schedules it, unless its unique key is
+// taken, in which case it returns the ID of the job holding the key
+var enqueueScript = redis.NewScript(`
+if KEYS[4] ~= "" then
+	if not redis.call("SET", KEYS[4], ARGV[2], "NX", "PX", ARGV[5]) then
+		return redis.call("GET", KEYS[4])
+	end
+end
+redis.call("SET", KEYS[1], ARGV[1])
+redis.call("ZADD", KEYS[2], ARGV[3], ARGV[2])
+redis.call("SADD", KEYS[3], ARGV[4])
+return ARGV[2]
+`)
+
+// retryScript moves a dead job back to its pending set, reacquiring its
+// unique key. It returns 0 if the job isn't dead and -1 if the key is taken.
+var retryScript = redis.NewScript(`
+if not redis.call("ZSCORE", KEYS[1], ARGV[2]) then
+	return 0
+end
+if KEYS[4] ~= "" then
+	if not redis.call("SET", KEYS[4], ARGV[2], "NX", "PX", ARGV[4]) then
+		return -1
+	end
+end
+redis.call("ZREM", KEYS[1], ARGV[2])
+redis.call("SET", KEYS[2], ARGV[1])
+redis.call("ZADD", KEYS[3], ARGV[3], ARGV[2])
+return 1
+`)
+
+// Enqueue schedules a job of jobType with payload marshalled to JSON. For
+// unique jobs whose key is taken, it returns the existing job's ID and
+// doesn't enqueue anything.
+func (c *Client) Enqueue(ctx context.Context, jobType string, payload interface{}, opts Options) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	job := Job{
+		ID:          uuid.NewString(),
+		Queue:       opts.Queue,
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: opts.MaxAttempts,
+		UniqueKey:   opts.UniqueKey,
+		UniqueFor:   opts.UniqueFor,
+		CreatedAt:   now,
+		RunAt:       now.Add(opts.Delay),
+	}
+	if job.Queue == "" {
+		job.Queue = DefaultQueue
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = c.maxAttempts
+	}
+	if job.UniqueKey != "" && job.UniqueFor <= 0 {
+		job.UniqueFor = defaultUniqueFor
+	}
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+
+	return enqueueScript.Run(ctx, c.rdb,
+		[]string{jobKey(job.ID), pendingKey(job.Queue), queuesKey(), job.uniqueKey()},
+		encoded, job.ID, job.RunAt.UnixMilli(), job.Queue, job.UniqueFor.Milliseconds(),
+	).Text()
+}
+
+// Queues returns the names of the queues that have been used
+func (c *Client) Queues(ctx context.Context) ([]string, error) {
+	return c.rdb.SMembers(ctx, queuesKey()).Result()
+}
+
+// Stats counts the jobs of every queue
+func (c *Client) Stats(ctx context.Context) ([]QueueStats, error) {
+	queues, err := c.Queues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := c.rdb.Pipeline()
+	type counts struct{ ready, scheduled, active, failed *redis.IntCmd }
+	cmds := make([]counts, len(queues))
+	for i, queue := range queues {
+		cmds[i] = counts{
+			ready:     pipe.ZCount(ctx, pendingKey(queue), "-inf", now),
+			scheduled: pipe.ZCount(ctx, pendingKey(queue), "("+now, "+inf"),
+			active:    pipe.ZCard(ctx, activeKey(queue)),
+			failed:    pipe.ZCard(ctx, failedKey(queue)),
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	stats := make([]QueueStats, len(queues))
+	for i, queue := range queues {
+		stats[i] = QueueStats{
+			Name:      queue,
+			Ready:     cmds[i].ready.Val(),
+			Scheduled: cmds[i].scheduled.Val(),
+			Active:    cmds[i].active.Val(),
+			Failed:    cmds[i].failed.Val(),
+		}
+	}
+	return stats, nil
+}
+
+// Failed returns the dead jobs of queue, most recent first
+func (c *Client) Failed(ctx context.Context, queue string, limit, offset int) ([]Job, error) {
+	ids, err := c.rdb.ZRevRange(ctx, failedKey(queue), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := c.Get(ctx, id)
+		if errors.Is(err, ErrJobNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+// Get returns a job by ID. Finished jobs are deleted, so only queued,
+// running and dead jobs are found.
+func (c *Client) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := c.rdb.Get(ctx, jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Retry moves a dead job back to its queue with a fresh set of attempts.
+// Unique jobs take their key back, failing while another job holds it.
+func (c *Client) Retry(ctx context.Context, id string) (*Job, error) {
+	job, err := c.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.FailedAt == nil {
+		return nil, ErrJobNotFailed
+	}
+	job.Attempts = 0
+	job.LastError = ""
+	job.FailedAt = nil
+	job.RunAt = time.Now()
+	if job.UniqueKey != "" && job.UniqueFor <= 0 {
+		// Enqueued before the hold was stored on the job
+		job.UniqueFor = defaultUniqueFor
+	}
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	moved, err := retryScript.Run(ctx, c.rdb,
+		[]string{failedKey(job.Queue), jobKey(job.ID), pendingKey(job.Queue), job.uniqueKey()},
+		encoded, job.ID, job.RunAt.UnixMilli(), job.UniqueFor.Milliseconds(),
+	).Int()
+	switch {
+	case err != nil:
+		return nil, err
+	case moved == 0:
+		// Another request retried it first
+		return nil, ErrJobNotFailed
+	case moved < 0:
+		return nil, ErrUniqueKeyTaken
+	}
+	return job, nil
+}
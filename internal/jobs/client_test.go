@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// newTestRedis returns a client of an in-memory Redis server
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+// kill runs the due jobs of queue once with a failing handler, leaving
+// those out of attempts dead
+func kill(t *testing.T, rdb *redis.Client, queue string) {
+	t.Helper()
+	worker := NewWorker(rdb, WorkerConfig{Queues: []string{queue}, VisibilityTimeout: time.Minute}, zap.NewNop())
+	worker.Handle("send", func(ctx context.Context, job *Job) error {
+		return errors.New("unavailable")
+	})
+	for {
+		job, err := worker.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job == nil {
+			return
+		}
+		worker.process(job)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		wantQueue       string
+		wantMaxAttempts int
+		wantDelay       time.Duration
+	}{
+		{name: "defaults", wantQueue: DefaultQueue, wantMaxAttempts: 5},
+		{name: "options", opts: Options{Queue: "mail", MaxAttempts: 2, Delay: time.Hour}, wantQueue: "mail", wantMaxAttempts: 2, wantDelay: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb := newTestRedis(t)
+			client := NewClient(rdb, 5)
+			ctx := context.Background()
+
+			id, err := client.Enqueue(ctx, "send", map[string]string{"to": "a@b.co"}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			job, err := client.Get(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Queue != tt.wantQueue || job.MaxAttempts != tt.wantMaxAttempts {
+				t.Errorf("job queue %q with %d attempts, want %q with %d", job.Queue, job.MaxAttempts, tt.wantQueue, tt.wantMaxAttempts)
+			}
+			var payload map[string]string
+			if err := job.Decode(&payload); err != nil || payload["to"] != "a@b.co" {
+				t.Errorf("payload = %v, %v", payload, err)
+			}
+
+			score, err := rdb.ZScore(ctx, pendingKey(tt.wantQueue), id).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := time.UnixMilli(int64(score)).Sub(job.CreatedAt); got < tt.wantDelay-time.Second || got > tt.wantDelay+time.Second {
+				t.Errorf("job runs %s after it was created, want %s", got, tt.wantDelay)
+			}
+			queues, err := client.Queues(ctx)
+			if err != nil || len(queues) != 1 || queues[0] != tt.wantQueue {
+				t.Errorf("Queues() = %v, %v; want [%s]", queues, err, tt.wantQueue)
+			}
+		})
+	}
+}
+
+func TestEnqueueUnique(t *testing.T) {
+	tests := []struct {
+		name     string
+		second   Options
+		wantSame bool
+	}{
+		{name: "same key", second: Options{UniqueKey: "user-1"}, wantSame: true},
+		{name: "other key", second: Options{UniqueKey: "user-2"}},
+		{name: "other queue", second: Options{Queue: "mail", UniqueKey: "user-1"}},
+		{name: "not unique", second: Options{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(newTestRedis(t), 5)
+			ctx := context.Background()
+
+			first, err := client.Enqueue(ctx, "send", nil, Options{UniqueKey: "user-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := client.Enqueue(ctx, "send", nil, tt.second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (first == second) != tt.wantSame {
+				t.Errorf("enqueued %s then %s, want same job %v", first, second, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestRetryUnique(t *testing.T) {
+	tests := []struct {
+		name    string
+		takeKey bool
+		wantErr error
+	}{
+		{name: "key free"},
+		{name: "key taken", takeKey: true, wantErr: ErrUniqueKeyTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb := newTestRedis(t)
+			client := NewClient(rdb, 1)
+			ctx := context.Background()
+
+			dead, err := client.Enqueue(ctx, "send", nil, Options{UniqueKey: "user-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			kill(t, rdb, DefaultQueue)
+			holder := dead
+			if tt.takeKey {
+				if holder, err = client.Enqueue(ctx, "send", nil, Options{UniqueKey: "user-1"}); err != nil {
+					t.Fatal(err)
+				}
+				if holder == dead {
+					t.Fatal("the dead job still held its unique key")
+				}
+			}
+
+			_, err = client.Retry(ctx, dead)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := rdb.Get(ctx, uniqueKey(DefaultQueue, "user-1")).Result(); got != holder {
+				t.Errorf("unique key held by %s, want %s", got, holder)
+			}
+			failed, err := client.Failed(ctx, DefaultQueue, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stillDead := len(failed) == 1; stillDead != (tt.wantErr != nil) {
+				t.Errorf("failed jobs = %d after the retry", len(failed))
+			}
+			if _, err := client.Retry(ctx, dead); tt.wantErr == nil && !errors.Is(err, ErrJobNotFailed) {
+				t.Errorf("second Retry() error = %v, want %v", err, ErrJobNotFailed)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	worker := NewWorker(nil, WorkerConfig{RetryBaseDelay: time.Second, RetryMaxDelay: 10 * time.Second}, zap.NewNop())
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 50, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		// Up to 20% jitter is added
+		if got := worker.backoff(tt.attempts); got < tt.want || got >= tt.want+tt.want/5 {
+			t.Errorf("backoff(%d) = %s, want %s plus jitter", tt.attempts, got, tt.want)
+		}
+	}
+}
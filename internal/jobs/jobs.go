@@ -0,0 +1,75 @@
+// Package jobs is a Redis backed job queue. Jobs carry a JSON payload, run
+// on named queues and can be delayed, retried with backoff and made unique.
+// A job that outlives its visibility timeout, e.g. because its worker died,
+// is handed to another worker.
+//
+// Redis layout, all under the "jobs:" prefix:
+//
+//	jobs:queues           set of queue names
+//	jobs:job:<id>         job JSON
+//	jobs:<queue>:pending  zset of job IDs scored by when they may run
+//	jobs:<queue>:active   zset of running job IDs scored by visibility deadline
+//	jobs:<queue>:failed   zset of dead job IDs scored by when they failed
+//	jobs:unique:<queue>:<key>  ID of the queued job holding a unique key
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DefaultQueue is used when a job is enqueued without a queue
+const DefaultQueue = "default"
+
+// defaultUniqueFor is how long unique keys are held unless Options say otherwise
+const defaultUniqueFor = 24 * time.Hour
+
+// Job is a unit of work
+type Job struct {
+	ID          string          `json:"id"`
+	Queue       string          `json:"queue"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	UniqueFor   time.Duration   `json:"unique_for,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	RunAt       time.Time       `json:"run_at"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`
+}
+
+// uniqueKey returns the Redis key of the job's unique key, or "" if it has none
+func (j *Job) uniqueKey() string {
+	if j.UniqueKey == "" {
+		return ""
+	}
+	return uniqueKey(j.Queue, j.UniqueKey)
+}
+
+// Decode unmarshals the job's payload into v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// QueueStats counts the jobs of a queue by state
+type QueueStats struct {
+	Name      string `json:"name"`
+	Ready     int64  `json:"ready"`
+	Scheduled int64  `json:"scheduled"`
+	Active    int64  `json:"active"`
+	Failed    int64  `json:"failed"`
+}
+
+const keyPrefix = "jobs:"
+
+func queuesKey() string              { return keyPrefix + "queues" }
+func jobKey(id string) string        { return keyPrefix + "job:" + id }
+func pendingKey(queue string) string { return keyPrefix + queue + ":pending" }
+func activeKey(queue string) string  { return keyPrefix + queue + ":active" }
+func failedKey(queue string) string  { return keyPrefix + queue + ":failed" }
+
+func uniqueKey(queue, key string) string {
+	return keyPrefix + "unique:" + queue + ":" + key
+}
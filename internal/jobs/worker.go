@@ -0,0 +1,332 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Handler runs a job. Returning an error schedules a retry until the job is
+// out of attempts. ctx is cancelled if shutdown runs out of time.
+type Handler func(ctx context.Context, job *Job) error
+
+// WorkerConfig tunes a Worker
+type WorkerConfig struct {
+	// Queues are polled in order, so earlier queues take priority
+	Queues      []string
+	Concurrency int
+	// PollInterval is how long an idle worker waits before polling again
+	PollInterval time.Duration
+	// VisibilityTimeout is how long a job may run before another worker
+	// assumes it was lost; running jobs extend it while they are alive
+	VisibilityTimeout time.Duration
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between attempts
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// Worker runs jobs from the configured queues with a pool of goroutines
+type Worker struct {
+	rdb      *redis.Client
+	cfg      WorkerConfig
+	logger   *zap.Logger
+	handlers map[string]Handler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a worker. Register handlers before calling Start.
+func NewWorker(rdb *redis.Client, cfg WorkerConfig, logger *zap.Logger) *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		rdb:      rdb,
+		cfg:      cfg,
+		logger:   logger,
+		handlers: map[string]Handler{},
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Handle registers the handler for jobType
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Start launches the worker pool and the reaper of timed out jobs
+func (w *Worker) Start() {
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.run()
+	}
+	w.wg.Add(1)
+	go w.reap()
+}
+
+// Stop stops taking new jobs and waits for running ones. If ctx expires
+// first, running handlers are cancelled and their jobs become visible again
+// once their visibility timeout passes.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.once.Do(func() { close(w.stop) })
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) run() {
+	defer w.wg.Done()
+	for {
+		if w.stopped() {
+			return
+		}
+		job, err := w.next()
+		if err != nil {
+			w.logger.Error("Failed to fetch job", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+		w.process(job)
+	}
+}
+
+// dequeueScript moves the first due job of a queue to its active set
+var dequeueScript = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)
+if #ids == 0 then
+	return false
+end
+redis.call("ZREM", KEYS[1], ids[1])
+redis.call("ZADD", KEYS[2], ARGV[2], ids[1])
+return ids[1]
+`)
+
+// next claims the first due job across the queues, or returns nil
+func (w *Worker) next() (*Job, error) {
+	for _, queue := range w.cfg.Queues {
+		now := time.Now()
+		id, err := dequeueScript.Run(w.ctx, w.rdb,
+			[]string{pendingKey(queue), activeKey(queue)},
+			now.UnixMilli(), now.Add(w.cfg.VisibilityTimeout).UnixMilli(),
+		).Text()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := w.rdb.Get(w.ctx, jobKey(id)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Deleted while queued; drop the dangling ID
+			w.rdb.ZRem(w.ctx, activeKey(queue), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("jobs: decoding job %s: %w", id, err)
+		}
+		return &job, nil
+	}
+	return nil, nil
+}
+
+// process runs job and records the outcome
+func (w *Worker) process(job *Job) {
+	logger := w.logger.With(zap.String("job_id", job.ID), zap.String("job_type", job.Type), zap.String("queue", job.Queue))
+
+	// Count the attempt before running, so a job that keeps crashing its
+	// worker still runs out of attempts
+	job.Attempts++
+	if job.Attempts > job.MaxAttempts {
+		w.finish(job, errors.New("visibility timeout exceeded on the last attempt"), logger)
+		return
+	}
+	if err := w.save(job); err != nil {
+		logger.Error("Failed to record job attempt", zap.Error(err))
+	}
+
+	start := time.Now()
+	err := w.execute(job)
+	metrics.JobDuration.WithLabelValues(job.Queue, job.Type).Observe(time.Since(start).Seconds())
+	w.finish(job, err, logger)
+}
+
+// execute calls the job's handler, extending its visibility while it runs
+func (w *Worker) execute(job *Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		job.Attempts = job.MaxAttempts
+		return fmt.Errorf("jobs: no handler for job type %q", job.Type)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go w.heartbeat(job, done)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jobs: handler panicked: %v", r)
+		}
+	}()
+	return handler(w.ctx, job)
+}
+
+// heartbeat pushes the job's visibility deadline forward until done
+func (w *Worker) heartbeat(job *Job, done <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.VisibilityTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			deadline := float64(time.Now().Add(w.cfg.VisibilityTimeout).UnixMilli())
+			w.rdb.ZAddXX(w.ctx, activeKey(job.Queue), redis.Z{Score: deadline, Member: job.ID})
+		}
+	}
+}
+
+// finish deletes a successful job, schedules a retry or moves the job to
+// the failed set
+func (w *Worker) finish(job *Job, runErr error, logger *zap.Logger) {
+	ctx := context.Background()
+	pipe := w.rdb.TxPipeline()
+	pipe.ZRem(ctx, activeKey(job.Queue), job.ID)
+	status := "succeeded"
+	switch {
+	case runErr == nil:
+		pipe.Del(ctx, jobKey(job.ID))
+		if job.UniqueKey != "" {
+			pipe.Del(ctx, uniqueKey(job.Queue, job.UniqueKey))
+		}
+	case job.Attempts >= job.MaxAttempts:
+		status = "failed"
+		now := time.Now()
+		job.LastError = runErr.Error()
+		job.FailedAt = &now
+		w.queueSave(ctx, pipe, job)
+		pipe.ZAdd(ctx, failedKey(job.Queue), redis.Z{Score: float64(now.UnixMilli()), Member: job.ID})
+		if job.UniqueKey != "" {
+			pipe.Del(ctx, uniqueKey(job.Queue, job.UniqueKey))
+		}
+		logger.Error("Job failed permanently", zap.Int("attempts", job.Attempts), zap.Error(runErr))
+	default:
+		status = "retried"
+		delay := w.backoff(job.Attempts)
+		job.LastError = runErr.Error()
+		job.RunAt = time.Now().Add(delay)
+		w.queueSave(ctx, pipe, job)
+		pipe.ZAdd(ctx, pendingKey(job.Queue), redis.Z{Score: float64(job.RunAt.UnixMilli()), Member: job.ID})
+		logger.Warn("Job failed, will retry", zap.Int("attempts", job.Attempts), zap.Duration("retry_in", delay), zap.Error(runErr))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Failed to record job result", zap.Error(err))
+	}
+	metrics.JobsProcessedTotal.WithLabelValues(job.Queue, job.Type, status).Inc()
+}
+
+func (w *Worker) save(job *Job) error {
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return w.rdb.Set(w.ctx, jobKey(job.ID), encoded, 0).Err()
+}
+
+func (w *Worker) queueSave(ctx context.Context, pipe redis.Pipeliner, job *Job) {
+	if encoded, err := json.Marshal(job); err == nil {
+		pipe.Set(ctx, jobKey(job.ID), encoded, 0)
+	}
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled
+// per failed attempt, capped at the max delay, with up to 20% jitter
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryBaseDelay
+	for i := 1; i < attempts && delay < w.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > w.cfg.RetryMaxDelay {
+		delay = w.cfg.RetryMaxDelay
+	}
+	if jitter := int64(delay / 5); jitter > 0 {
+		delay += time.Duration(rand.Int64N(jitter))
+	}
+	return delay
+}
+
+// requeueScript returns active jobs past their visibility deadline to the queue
+var requeueScript = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	redis.call("ZADD", KEYS[2], ARGV[1], id)
+end
+return #ids
+`)
+
+// reap periodically requeues jobs whose worker stopped extending them
+func (w *Worker) reap() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+		for _, queue := range w.cfg.Queues {
+			now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			count, err := requeueScript.Run(w.ctx, w.rdb, []string{activeKey(queue), pendingKey(queue)}, now).Int()
+			if err != nil {
+				w.logger.Error("Failed to requeue timed out jobs", zap.String("queue", queue), zap.Error(err))
+				continue
+			}
+			if count > 0 {
+				w.logger.Warn("Requeued timed out jobs", zap.String("queue", queue), zap.Int("count", count))
+			}
+		}
+	}
+}
+
+func (w *Worker) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+)
+
+// dkimHeaders are the signed header fields, following RFC 6376 section 5.4.1
+var dkimHeaders = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"}
+
+// newDKIMOptions loads the signing key, or returns nil when signing is disabled
+func newDKIMOptions(cfg config.DKIMConfig) (*dkim.SignOptions, error) {
+	if cfg.PrivateKeyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: reading DKIM key: %w", err)
+	}
+	signer, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parsing DKIM key: %w", err)
+	}
+	return &dkim.SignOptions{
+		Domain:                 cfg.Domain,
+		Selector:               cfg.Selector,
+		Signer:                 signer,
+		HeaderCanonicalization: dkim.CanonicalizationRelaxed,
+		BodyCanonicalization:   dkim.CanonicalizationRelaxed,
+		HeaderKeys:             dkimHeaders,
+	}, nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS #1 RSA or PKCS #8 RSA/Ed25519 key
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+// sign prepends a DKIM-Signature header to the raw message
+func sign(raw []byte, options *dkim.SignOptions) ([]byte, error) {
+	var signed bytes.Buffer
+	if err := dkim.Sign(&signed, bytes.NewReader(raw), options); err != nil {
+		return nil, err
+	}
+	return signed.Bytes(), nil
+}
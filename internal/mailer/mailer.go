@@ -0,0 +1,91 @@
+// Package mailer delivers rendered emails through a configurable transport:
+// SMTP, the application log, .eml files or memory.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	stdmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-mail/mail"
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"go.uber.org/zap"
+)
+
+// Transport names accepted by EMAIL_TRANSPORT
+const (
+	TransportSMTP   = "smtp"
+	TransportLog    = "log"
+	TransportFile   = "file"
+	TransportMemory = "memory"
+)
+
+// Message is an email ready to deliver. HTML is optional; when set the email
+// is sent as multipart/alternative.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Pinger is implemented by mailers that can check their server is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// IdleCloser is implemented by mailers that keep connections open between
+// sends. CloseIdle drops connections unused for longer than idle.
+type IdleCloser interface {
+	CloseIdle(idle time.Duration)
+}
+
+// New creates the mailer for the configured transport
+func New(cfg config.EmailConfig, smtpCfg config.SMTPConfig, logger *zap.Logger) (Mailer, error) {
+	switch cfg.Transport {
+	case TransportSMTP:
+		return NewSMTP(smtpCfg)
+	case TransportLog:
+		return NewLog(logger), nil
+	case TransportFile:
+		return NewFile(cfg.FileDir)
+	case TransportMemory:
+		return NewMemory(), nil
+	}
+	return nil, fmt.Errorf("mailer: unknown transport %q", cfg.Transport)
+}
+
+// build converts msg to a go-mail message
+func build(msg *Message) *mail.Message {
+	m := mail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetHeader("Message-ID", messageID(msg.From))
+	m.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		m.AddAlternative("text/html", msg.HTML)
+	}
+	return m
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := stdmail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	return "<" + uuid.NewString() + "@" + domain + ">"
+}
@@ -0,0 +1,238 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	stdmail "net/mail"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/go-mail/mail"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// SMTP sends through an SMTP server over a pool of reusable connections,
+// optionally DKIM signing each message and limiting the send rate
+type SMTP struct {
+	dialer  *mail.Dialer
+	dkim    *dkim.SignOptions
+	limiter *rate.Limiter
+	// slots holds a token per connection in use, bounding the pool
+	slots chan struct{}
+
+	mu   sync.Mutex
+	idle []*pooledConn
+}
+
+// pooledConn is an open connection waiting in the pool
+type pooledConn struct {
+	mail.SendCloser
+	lastUsed time.Time
+}
+
+// NewSMTP creates an SMTP mailer. No connection is made until the first send.
+// Without a username it doesn't authenticate.
+func NewSMTP(cfg config.SMTPConfig) (*SMTP, error) {
+	// The dialer only authenticates when the username is set
+	dialer := mail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	dialer.Timeout = cfg.Timeout
+	// Failed sends are redialed by the pool instead
+	dialer.RetryFailure = false
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: reading SMTP CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.New("mailer: no certificates found in SMTP CA file")
+		}
+		tlsConfig.RootCAs = roots
+	}
+	dialer.TLSConfig = tlsConfig
+	switch cfg.TLSMode {
+	case "implicit":
+		dialer.SSL = true
+	case "none":
+		dialer.SSL = false
+		dialer.StartTLSPolicy = mail.NoStartTLS
+	default:
+		dialer.SSL = false
+		dialer.StartTLSPolicy = mail.MandatoryStartTLS
+	}
+
+	options, err := newDKIMOptions(cfg.DKIM)
+	if err != nil {
+		return nil, err
+	}
+
+	poolSize := max(cfg.PoolSize, 1)
+	s := &SMTP{
+		dialer: dialer,
+		dkim:   options,
+		slots:  make(chan struct{}, poolSize),
+	}
+	if cfg.RateLimit > 0 {
+		// Space sends evenly rather than allowing a burst each minute
+		s.limiter = rate.NewLimiter(rate.Limit(float64(cfg.RateLimit)/60), 1)
+	}
+	return s, nil
+}
+
+// Ping checks that the SMTP server accepts TCP connections
+func (s *SMTP) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", s.dialer.Host, s.dialer.Port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Send delivers msg over a pooled connection
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	ctx, span := tracing.Tracer().Start(ctx, "smtp.send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.ServerAddress(s.dialer.Host),
+			semconv.ServerPort(s.dialer.Port),
+			attribute.Bool("smtp.dkim", s.dkim != nil),
+		),
+	)
+	defer span.End()
+
+	if err := s.send(ctx, msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *SMTP) send(ctx context.Context, msg *Message) error {
+	from, err := stdmail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid sender: %w", err)
+	}
+	to, err := stdmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid recipient: %w", err)
+	}
+	var raw bytes.Buffer
+	if _, err := build(msg).WriteTo(&raw); err != nil {
+		return err
+	}
+	data := raw.Bytes()
+	if s.dkim != nil {
+		if data, err = sign(data, s.dkim); err != nil {
+			return fmt.Errorf("mailer: DKIM signing: %w", err)
+		}
+	}
+
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	conn, reused, err := s.get()
+	if err != nil {
+		return err
+	}
+	err = conn.Send(from.Address, []string{to.Address}, bytes.NewReader(data))
+	if err != nil && reused {
+		// The server may have closed the idle connection; redial once
+		_ = conn.Close()
+		if conn, err = s.dial(); err != nil {
+			return err
+		}
+		err = conn.Send(from.Address, []string{to.Address}, bytes.NewReader(data))
+	}
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	s.put(conn)
+	return nil
+}
+
+// get takes an idle connection from the pool or dials a new one. The caller
+// must hold a slot.
+func (s *SMTP) get() (*pooledConn, bool, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		conn := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return conn, true, nil
+	}
+	s.mu.Unlock()
+	conn, err := s.dial()
+	return conn, false, err
+}
+
+func (s *SMTP) dial() (*pooledConn, error) {
+	conn, err := s.dialer.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return &pooledConn{SendCloser: conn}, nil
+}
+
+// put returns a healthy connection to the pool
+func (s *SMTP) put(conn *pooledConn) {
+	conn.lastUsed = time.Now()
+	s.mu.Lock()
+	s.idle = append(s.idle, conn)
+	s.mu.Unlock()
+}
+
+// CloseIdle closes the pooled connections unused for longer than idle
+func (s *SMTP) CloseIdle(idle time.Duration) {
+	s.mu.Lock()
+	var keep, stale []*pooledConn
+	for _, conn := range s.idle {
+		if time.Since(conn.lastUsed) > idle {
+			stale = append(stale, conn)
+		} else {
+			keep = append(keep, conn)
+		}
+	}
+	s.idle = keep
+	s.mu.Unlock()
+	for _, conn := range stale {
+		_ = conn.Close()
+	}
+}
+
+// Close closes every pooled connection
+func (s *SMTP) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle = nil
+	s.mu.Unlock()
+	for _, conn := range idle {
+		_ = conn.Close()
+	}
+	return nil
+}
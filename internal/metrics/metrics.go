@@ -0,0 +1,165 @@
+// Package metrics defines the application's Prometheus collectors and the
+// instrumentation hooks for HTTP, GORM and Redis.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every collector exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal counts requests by method, route template and status
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route template and status
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration observes GORM query latency by operation and table
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Database query latency in seconds.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	// RedisCommandDuration observes Redis command latency by command
+	RedisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_duration_seconds",
+		Help:    "Redis command latency in seconds.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command", "status"})
+
+	// AuthLoginsTotal counts login attempts by result (success, failure or step_up)
+	AuthLoginsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Total number of login attempts.",
+	}, []string{"result"})
+
+	// AuthRegistrationsTotal counts successful registrations
+	AuthRegistrationsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "Total number of user registrations.",
+	})
+
+	// OTPSentTotal counts OTP codes sent by channel
+	OTPSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_otp_sent_total",
+		Help: "Total number of OTP codes sent.",
+	}, []string{"channel"})
+
+	// OTPQuotaExceededTotal counts OTP requests refused by the per-destination quotas
+	OTPQuotaExceededTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_otp_quota_exceeded_total",
+		Help: "Total number of OTP requests refused by the per-destination quotas, by channel.",
+	}, []string{"channel"})
+
+	// EmailsSentTotal counts emails delivered to the SMTP server
+	EmailsSentTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "emails_sent_total",
+		Help: "Total number of emails sent.",
+	})
+
+	// EmailsFailedTotal counts emails that could not be sent
+	EmailsFailedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "emails_failed_total",
+		Help: "Total number of emails that failed to send.",
+	})
+
+	// EmailsDeadLetteredTotal counts emails that ran out of delivery attempts
+	EmailsDeadLetteredTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "emails_dead_lettered_total",
+		Help: "Total number of emails moved to the dead-letter state.",
+	})
+
+	// JobsProcessedTotal counts job runs by queue, type and status (succeeded, retried or failed)
+	JobsProcessedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Total number of background job runs.",
+	}, []string{"queue", "type", "status"})
+
+	// JobDuration measures job handler run time
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_duration_seconds",
+		Help:    "Background job run time in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"queue", "type"})
+
+	// ScheduledRunsTotal counts scheduled job runs by job and status (succeeded or failed)
+	ScheduledRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduled_runs_total",
+		Help: "Total number of scheduled job runs.",
+	}, []string{"job", "status"})
+
+	// ScheduledRunDuration measures scheduled job run time
+	ScheduledRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scheduled_run_duration_seconds",
+		Help:    "Scheduled job run time in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+
+	// WebhookAttemptsTotal counts webhook requests by event and result (success or failure)
+	WebhookAttemptsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_attempts_total",
+		Help: "Total number of webhook delivery attempts.",
+	}, []string{"event", "result"})
+
+	// EventsPublishedTotal counts domain events published on the event bus
+	EventsPublishedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_published_total",
+		Help: "Total number of domain events published.",
+	}, []string{"event"})
+
+	// EventHandlerFailuresTotal counts event subscribers that returned an error or panicked
+	EventHandlerFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "event_handler_failures_total",
+		Help: "Total number of failed domain event handlers.",
+	}, []string{"event"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		RedisCommandDuration,
+		AuthLoginsTotal,
+		AuthRegistrationsTotal,
+		OTPSentTotal,
+		OTPQuotaExceededTotal,
+		EmailsSentTotal,
+		EmailsFailedTotal,
+		EmailsDeadLetteredTotal,
+		JobsProcessedTotal,
+		JobDuration,
+		ScheduledRunsTotal,
+		ScheduledRunDuration,
+		WebhookAttemptsTotal,
+		EventsPublishedTotal,
+		EventHandlerFailuresTotal,
+	)
+}
+
+// RegisterDBStats exposes the sql.DB connection pool stats under dbName
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
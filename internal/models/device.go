@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// KnownDevice is a user agent and IP address a user has signed in from.
+// Only the most recently used devices of each user are kept.
+type KnownDevice struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	UserAgent string    `json:"user_agent" gorm:"not null"`
+	IP        string    `json:"ip" gorm:"not null"`
+	Location  string    `json:"location"`
+	// Latitude and Longitude are set when the IP address could be located
+	Latitude    *float64  `json:"-"`
+	Longitude   *float64  `json:"-"`
+	FirstSeenAt time.Time `json:"first_seen_at" gorm:"not null"`
+	LastSeenAt  time.Time `json:"last_seen_at" gorm:"not null"`
+}
+
+// BeforeCreate sets UUID before creating
+func (d *KnownDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// ClientInfo describes where a request came from
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// RevokeSessionsInput signs out every session from a new sign-in alert
+type RevokeSessionsInput struct {
+	// Token is the token parameter of the alert's link
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox email states
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusDead    = "dead"
+)
+
+// OutboxEmail is an email waiting in the outbox for the delivery worker.
+// Emails that keep failing end up dead until an admin retries them.
+type OutboxEmail struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	To            string     `json:"to" gorm:"column:recipient;not null"`
+	Subject       string     `json:"subject" gorm:"not null"`
+	Text          string     `json:"-" gorm:"column:text_body;not null"`
+	HTML          string     `json:"-" gorm:"column:html_body;not null"`
+	Status        string     `json:"status" gorm:"not null;default:pending"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"not null"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null"`
+	LockedUntil   *time.Time `json:"-"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName stores outbox emails in email_outbox
+func (OutboxEmail) TableName() string {
+	return "email_outbox"
+}
+
+// BeforeCreate sets the ID and schedules the first attempt
+func (e *OutboxEmail) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = EmailStatusPending
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is a domain event waiting to be published. It is written in
+// the transaction that raised it and deleted once subscribers have run
+// without error; until then it is retried with backoff.
+type OutboxEvent struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key"`
+	Name        string          `gorm:"not null"`
+	Payload     json.RawMessage `gorm:"type:jsonb;serializer:json;not null"`
+	LockedUntil *time.Time
+	// Attempts counts failed publishes, LastError is the latest failure
+	Attempts  int    `gorm:"not null;default:0"`
+	LastError string `gorm:"not null;default:''"`
+	CreatedAt time.Time
+}
+
+// TableName stores outbox events in event_outbox
+func (OutboxEvent) TableName() string {
+	return "event_outbox"
+}
+
+// BeforeCreate sets UUID before creating
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification categories, each with its own delivery preferences
+const (
+	NotificationCategorySecurity = "security"
+	NotificationCategoryAccount  = "account"
+)
+
+// NotificationCategories lists every category, in the order preferences are shown
+var NotificationCategories = []string{NotificationCategorySecurity, NotificationCategoryAccount}
+
+// Notification types
+const (
+	NotificationTypeWelcome         = "welcome"
+	NotificationTypePasswordChanged = "password_changed"
+	NotificationTypeNewLogin        = "new_login"
+	NotificationTypeSuspiciousLogin = "suspicious_login"
+)
+
+// Notification is an entry in a user's inbox. Clients render it from its
+// type and payload.
+type Notification struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID       `json:"-" gorm:"type:uuid;not null"`
+	Category  string          `json:"category" gorm:"not null"`
+	Type      string          `json:"type" gorm:"not null"`
+	Payload   json.RawMessage `json:"payload" gorm:"type:jsonb;serializer:json;not null"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	// EventID is the bus event the notification was raised for, if any
+	EventID *uuid.UUID `json:"-" gorm:"type:uuid"`
+}
+
+// BeforeCreate sets UUID before creating
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// NotificationPage is one page of a user's inbox, newest first
+type NotificationPage struct {
+	Items []Notification `json:"items"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor  string `json:"next_cursor"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+// NotificationPreference decides where a user's notifications of one
+// category are delivered
+type NotificationPreference struct {
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	Category  string    `json:"category" gorm:"primary_key"`
+	Inbox     bool      `json:"inbox" gorm:"not null"`
+	Email     bool      `json:"email" gorm:"not null"`
+	Push      bool      `json:"push" gorm:"not null"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// DefaultNotificationPreference returns the preference of users who haven't
+// chosen one for category
+func DefaultNotificationPreference(userID uuid.UUID, category string) NotificationPreference {
+	return NotificationPreference{
+		UserID:   userID,
+		Category: category,
+		Inbox:    true,
+		Email:    category == NotificationCategorySecurity,
+		Push:     true,
+	}
+}
+
+// NotificationPreferenceInput changes the channels of one category; omitted
+// channels are left as they are
+type NotificationPreferenceInput struct {
+	Category string `json:"category" validate:"required,oneof=security account"`
+	Inbox    *bool  `json:"inbox"`
+	Email    *bool  `json:"email"`
+	Push     *bool  `json:"push"`
+}
+
+// NotificationPreferencesInput changes the preferences of several categories
+type NotificationPreferencesInput struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" validate:"required,min=1,dive"`
+}
+
+// NotificationsMarked reports how many notifications were marked read
+type NotificationsMarked struct {
+	Count int64 `json:"count"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scheduled run outcomes
+const (
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// ScheduledRun records one run of a scheduled job
+type ScheduledRun struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Job        string    `json:"job" gorm:"not null"`
+	Instance   string    `json:"instance" gorm:"not null"`
+	Status     string    `json:"status" gorm:"not null"`
+	Error      string    `json:"error,omitempty" gorm:"not null"`
+	StartedAt  time.Time `json:"started_at" gorm:"not null"`
+	FinishedAt time.Time `json:"finished_at" gorm:"not null"`
+	DurationMS int64     `json:"duration_ms" gorm:"column:duration_ms;not null"`
+}
+
+// BeforeCreate sets UUID before creating
+func (r *ScheduledRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SigningKey is an HMAC key used to sign JWTs, identified by the token's kid header
+type SigningKey struct {
+	ID        string     `json:"id" gorm:"primary_key"`
+	Secret    string     `json:"-" gorm:"not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiresAt *time.Time `json:"retires_at" gorm:"index"`
+}
+
+// JWTSettings is the single row of JWT state that outlives any one key
+type JWTSettings struct {
+	ID bool `gorm:"primary_key"`
+	// SecretReplacedAt is when the first key replaced JWT_SECRET
+	SecretReplacedAt time.Time `gorm:"not null"`
+}
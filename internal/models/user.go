@@ -15,17 +15,35 @@ type User struct {
 	Password  string         `json:"-" gorm:"not null"`
 	FirstName string         `json:"first_name"`
 	LastName  string         `json:"last_name"`
+	Phone     string         `json:"phone" gorm:"not null;default:''"`
+	Role      string         `json:"role" gorm:"not null;default:user"`
+	Locale    string         `json:"locale" gorm:"not null"`
 	IsActive  bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// SessionsRevokedAt invalidates every token issued before it
+	SessionsRevokedAt *time.Time `json:"-"`
+	// PhoneVerifiedAt is set once the user confirmed Phone with a code; only
+	// verified numbers receive sign-in codes
+	PhoneVerifiedAt *time.Time `json:"-"`
 }
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // BeforeCreate sets UUID before creating
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
 	return nil
 }
 
@@ -36,20 +54,29 @@ type UserResponse struct {
 	Username  string    `json:"username"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	Phone     string    `json:"phone,omitempty"`
+	// PhoneVerified is set once the phone can receive sign-in codes
+	PhoneVerified bool      `json:"phone_verified"`
+	Role          string    `json:"role"`
+	Locale        string    `json:"locale,omitempty"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		Username:  u.Username,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
+		ID:            u.ID,
+		Email:         u.Email,
+		Username:      u.Username,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Phone:         u.Phone,
+		PhoneVerified: u.PhoneVerifiedAt != nil,
+		Role:          u.Role,
+		Locale:        u.Locale,
+		IsActive:      u.IsActive,
+		CreatedAt:     u.CreatedAt,
 	}
 }
 
@@ -65,6 +92,34 @@ type RegisterInput struct {
 	Password  string `json:"password" validate:"required,min=8"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	// Phone is an E.164 number that can receive OTP codes by SMS, e.g. "+14155550123"
+	Phone string `json:"phone" validate:"omitempty,e164"`
+	// Locale is the preferred language for messages and emails, e.g. "es"
+	Locale string `json:"locale" validate:"omitempty,locale"`
+}
+
+// OTPRequestInput asks for a one-time sign-in code
+type OTPRequestInput struct {
+	Email string `json:"email" validate:"required,email"`
+	// Channel delivers the code: email, sms or console. Defaults to the first enabled channel.
+	Channel string `json:"channel" validate:"omitempty,oneof=email sms console"`
+}
+
+// OTPRequestResponse tells the client how the code was sent
+type OTPRequestResponse struct {
+	Channel   string `json:"channel"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// PhoneVerifyInput confirms the user's phone number with the code texted to it
+type PhoneVerifyInput struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// OTPVerifyInput signs in with a one-time code
+type OTPVerifyInput struct {
+	Email string `json:"email" validate:"required,email"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
 }
 
 type AuthResponse struct {
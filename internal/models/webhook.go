@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook event types
+const (
+	WebhookEventUserCreated     = "user.created"
+	WebhookEventUserUpdated     = "user.updated"
+	WebhookEventUserDeleted     = "user.deleted"
+	WebhookEventUserLogin       = "user.login"
+	WebhookEventPasswordChanged = "password.changed"
+	// WebhookEventTest is only sent by the test endpoint, whatever the filter
+	WebhookEventTest = "webhook.test"
+)
+
+// Webhook delivery states
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookEndpoint receives the events it subscribes to
+type WebhookEndpoint struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	URL         string    `json:"url" gorm:"not null"`
+	Description string    `json:"description" gorm:"not null"`
+	// Secret signs deliveries; it is only returned when the endpoint is created
+	Secret string `json:"-" gorm:"not null"`
+	// Events filters the events sent to the endpoint, all when empty
+	Events    []string  `json:"events" gorm:"type:jsonb;serializer:json;not null"`
+	Active    bool      `json:"active" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate sets UUID before creating
+func (e *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Subscribed reports whether the endpoint wants event
+func (e *WebhookEndpoint) Subscribed(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// WebhookEndpointInput creates or replaces a webhook endpoint
+type WebhookEndpointInput struct {
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`
+	Description string   `json:"description" validate:"max=255"`
+	Events      []string `json:"events" validate:"dive,oneof=user.created user.updated user.deleted user.login password.changed"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+// WebhookEndpointSecret is a created endpoint along with its signing secret
+type WebhookEndpointSecret struct {
+	WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// WebhookEvent is the JSON body of a delivery
+type WebhookEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one endpoint
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	EndpointID uuid.UUID `json:"endpoint_id" gorm:"type:uuid;not null"`
+	// EventID is the ID of the bus event, so an endpoint gets one delivery
+	// per event however often the event is redelivered
+	EventID uuid.UUID `json:"event_id" gorm:"type:uuid;not null"`
+	Event   string    `json:"event" gorm:"not null"`
+	// Payload is the exact body sent on every attempt
+	Payload        json.RawMessage  `json:"payload" gorm:"type:jsonb;serializer:json;not null"`
+	Status         string           `json:"status" gorm:"not null;default:pending"`
+	Attempts       int              `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int              `json:"response_status,omitempty" gorm:"not null;default:0"`
+	LastError      string           `json:"last_error,omitempty" gorm:"not null"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	AttemptLog     []WebhookAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// BeforeCreate sets UUID before creating
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.Status == "" {
+		d.Status = DeliveryStatusPending
+	}
+	return nil
+}
+
+// WebhookAttempt records one HTTP request of a delivery
+type WebhookAttempt struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	DeliveryID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Attempt    int       `json:"attempt" gorm:"not null"`
+	StatusCode int       `json:"status_code,omitempty" gorm:"not null"`
+	Error      string    `json:"error,omitempty" gorm:"not null"`
+	DurationMS int64     `json:"duration_ms" gorm:"column:duration_ms;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate sets UUID before creating
+func (a *WebhookAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
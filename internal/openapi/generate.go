@@ -0,0 +1,244 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Operation documents a named route. The method and path come from the
+// route registration itself.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Auth marks routes that require a bearer token
+	Auth bool
+	// Query lists the accepted query parameters
+	Query []Parameter
+	// Request is a value of the JSON request body type, or nil for none
+	Request interface{}
+	// Status is the success status, 200 when zero
+	Status int
+	// Response is a value of the success payload type. It is wrapped in the
+	// success envelope unless Raw is set; nil means the payload is null.
+	Response interface{}
+	// Raw responses are written as is, with ContentType (default JSON)
+	Raw         bool
+	ContentType string
+	// Errors lists the documented error statuses besides the ones implied by
+	// Request (400), Auth (401) and every route (500)
+	Errors []int
+}
+
+// Parameter documents a query parameter
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+	// Example is a value of the parameter type, a string when nil
+	Example interface{}
+}
+
+// Operations maps route names to their documentation
+type Operations map[string]Operation
+
+// Merge combines several operation sets
+func Merge(sets ...Operations) Operations {
+	merged := Operations{}
+	for _, set := range sets {
+		for name, op := range set {
+			merged[name] = op
+		}
+	}
+	return merged
+}
+
+// Config controls document generation
+type Config struct {
+	Info Info
+	// Prefix selects the routes that must be documented, e.g. "/api/v1"
+	Prefix string
+	// Envelope is a value of the success envelope type; its "data" property
+	// is replaced by each operation's payload
+	Envelope interface{}
+	// ErrorBodies maps content types to a value of the error body type
+	ErrorBodies map[string]interface{}
+	// Operations documents the named routes
+	Operations Operations
+}
+
+// Generate builds the document for every route under cfg.Prefix. It fails
+// when a route has no documentation or documentation has no route, so the
+// published contract can't silently drift from the router.
+func Generate(app *fiber.App, cfg Config) (*Document, error) {
+	registry := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    cfg.Info,
+		Servers: []Server{{URL: "/"}},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	var undocumented []string
+	documented := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, cfg.Prefix) {
+			continue
+		}
+		op, ok := cfg.Operations[route.Name]
+		if !ok {
+			undocumented = append(undocumented, route.Method+" "+route.Path)
+			continue
+		}
+		documented[route.Name] = true
+
+		path, params := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		if !item.setOperation(route.Method, buildOperation(registry, cfg, route.Name, op, params)) {
+			undocumented = append(undocumented, route.Method+" "+route.Path)
+		}
+	}
+
+	var unrouted []string
+	for name := range cfg.Operations {
+		if !documented[name] {
+			unrouted = append(unrouted, name)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unrouted)
+	switch {
+	case len(undocumented) > 0:
+		return nil, fmt.Errorf("openapi: undocumented routes: %s", strings.Join(undocumented, ", "))
+	case len(unrouted) > 0:
+		return nil, fmt.Errorf("openapi: documented operations without a route: %s", strings.Join(unrouted, ", "))
+	}
+
+	doc.Components.Schemas = registry.schemas
+	return doc, nil
+}
+
+// buildOperation converts an Operation into its OpenAPI form
+func buildOperation(registry *schemaRegistry, cfg Config, name string, op Operation, params []ParameterObject) *OperationObject {
+	result := &OperationObject{
+		OperationID: name,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Parameters:  params,
+		Responses:   map[string]*ResponseObject{},
+	}
+	for _, param := range op.Query {
+		schema := &Schema{Type: "string"}
+		if param.Example != nil {
+			schema = registry.schemaFor(param.Example)
+		}
+		result.Parameters = append(result.Parameters, ParameterObject{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      schema,
+		})
+	}
+
+	errorStatuses := append([]int{}, op.Errors...)
+	if op.Request != nil {
+		result.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				fiber.MIMEApplicationJSON: {Schema: registry.schemaFor(op.Request)},
+			},
+		}
+		errorStatuses = append(errorStatuses, http.StatusBadRequest)
+	}
+	if op.Auth {
+		result.Security = []map[string][]string{{"bearerAuth": {}}}
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized)
+	}
+	errorStatuses = append(errorStatuses, http.StatusInternalServerError)
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	result.Responses[strconv.Itoa(status)] = successResponse(registry, cfg, op)
+
+	errorContent := map[string]*MediaType{}
+	for contentType, body := range cfg.ErrorBodies {
+		errorContent[contentType] = &MediaType{Schema: registry.schemaFor(body)}
+	}
+	for _, status := range errorStatuses {
+		result.Responses[strconv.Itoa(status)] = &ResponseObject{
+			Description: http.StatusText(status),
+			Content:     errorContent,
+		}
+	}
+	return result
+}
+
+// successResponse describes the success body, wrapping the payload in the
+// envelope unless the operation is raw. 101 and 204 responses have no body.
+func successResponse(registry *schemaRegistry, cfg Config, op Operation) *ResponseObject {
+	if op.Status == http.StatusSwitchingProtocols || op.Status == http.StatusNoContent {
+		return &ResponseObject{Description: http.StatusText(op.Status)}
+	}
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = fiber.MIMEApplicationJSON
+	}
+
+	payload := registry.schemaFor(op.Response)
+	if payload == nil {
+		payload = &Schema{Type: "null"}
+	}
+	if op.Raw || cfg.Envelope == nil {
+		if op.Response == nil && contentType != fiber.MIMEApplicationJSON {
+			payload = &Schema{Type: "string"}
+		}
+	} else {
+		payload = &Schema{AllOf: []*Schema{
+			registry.schemaFor(cfg.Envelope),
+			{Type: "object", Properties: map[string]*Schema{"data": payload}},
+		}}
+	}
+	return &ResponseObject{
+		Description: "Success",
+		Content:     map[string]*MediaType{contentType: {Schema: payload}},
+	}
+}
+
+// convertPath turns a Fiber path such as /users/:id into /users/{id} and
+// returns its path parameters
+func convertPath(path string) (string, []ParameterObject) {
+	segments := strings.Split(path, "/")
+	var params []ParameterObject
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimSuffix(segment[1:], "?")
+		segments[i] = "{" + name + "}"
+		params = append(params, ParameterObject{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
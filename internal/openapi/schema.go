@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaRegistry converts Go types into schemas, collecting named structs as
+// reusable components
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schemaFor returns the schema of v's type, or nil when v is nil
+func (r *schemaRegistry) schemaFor(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return r.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of t. Named structs are stored as components and
+// referenced by $ref.
+func (r *schemaRegistry) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(r.schema(t.Elem()))
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if reflect.PointerTo(t).Implements(textMarshalerType) {
+			return &Schema{Type: "string"}
+		}
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.ref(t)
+	}
+	return &Schema{}
+}
+
+// ref registers the named struct t as a component and returns a reference to it
+func (r *schemaRegistry) ref(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := r.schemas[name]; taken {
+			name = pathSegment(t.PkgPath()) + name
+		}
+		r.names[t] = name
+		// Reserve the name before recursing so self-referencing types terminate
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema describes the JSON encoding of a struct
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.structSchema(embedded)
+				for key, value := range inner.Properties {
+					schema.Properties[key] = value
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := r.schema(field.Type)
+		// Rules after dive apply to the elements of a slice
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		if i := slices.Index(rules, "dive"); i >= 0 {
+			if property.Items != nil {
+				applyRules(property.Items, strings.Join(rules[i+1:], ","))
+			}
+			rules = rules[:i]
+		}
+		if applyRules(property, strings.Join(rules, ",")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// applyRules maps validator rules onto schema keywords and reports whether
+// the field is required
+func applyRules(schema *Schema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "http_url":
+			schema.Format = "uri"
+		case "e164":
+			schema.Format = "e164"
+		case "min", "gte":
+			setBound(schema, param, &schema.MinLength, &schema.Minimum)
+		case "max", "lte":
+			setBound(schema, param, &schema.MaxLength, &schema.Maximum)
+		case "len":
+			setBound(schema, param, &schema.MinLength, &schema.Minimum)
+			setBound(schema, param, &schema.MaxLength, &schema.Maximum)
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+	}
+	return required
+}
+
+// setBound applies a min/max rule as a length for strings and as a value
+// bound for numbers
+func setBound(schema *Schema, param string, length **int, bound **float64) {
+	switch schema.Type {
+	case "string":
+		if n, err := strconv.Atoi(param); err == nil {
+			*length = &n
+		}
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(param, 64); err == nil {
+			*bound = &f
+		}
+	}
+}
+
+// nullable allows null in addition to the values schema accepts
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	}
+	if typ, ok := schema.Type.(string); ok {
+		schema.Type = []string{typ, "null"}
+	}
+	return schema
+}
+
+// pathSegment returns the last element of an import path, capitalized
+func pathSegment(pkgPath string) string {
+	segment := pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+	if segment == "" {
+		return ""
+	}
+	return strings.ToUpper(segment[:1]) + segment[1:]
+}
@@ -0,0 +1,14 @@
+package openapi
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed ui.html
+var uiTemplate string
+
+// UIPage returns the Swagger UI page that renders the document at specURL
+func UIPage(specURL string) []byte {
+	return []byte(strings.ReplaceAll(uiTemplate, "{{SPEC_URL}}", specURL))
+}
@@ -0,0 +1,387 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+
+// Issue describes one way a value failed its schema. Rules use the validator
+// tag names (required, email, min, max, oneof) so they render like struct tag
+// failures, plus type and format.
+type Issue struct {
+	// Path is the dotted JSON path of the value, empty for the whole body
+	Path  string
+	Rule  string
+	Param string
+	// String reports whether min/max apply to a string's length
+	String bool
+}
+
+// Validator checks requests and responses against a document
+type Validator struct {
+	doc    *Document
+	routes []validatorRoute
+}
+
+type validatorRoute struct {
+	segments []string
+	item     *PathItem
+}
+
+// NewValidator creates a validator with no document. Requests are let
+// through until Load is called.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// Load indexes doc's paths for Find
+func (v *Validator) Load(doc *Document) {
+	v.doc = doc
+	v.routes = v.routes[:0]
+	for path, item := range doc.Paths {
+		v.routes = append(v.routes, validatorRoute{segments: strings.Split(path, "/"), item: item})
+	}
+}
+
+// Find returns the operation serving method and path with its path
+// parameters, or nil when the path isn't documented
+func (v *Validator) Find(method, path string) (*OperationObject, map[string]string) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for _, route := range v.routes {
+		if len(route.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, segment := range route.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				params[segment[1:len(segment)-1]] = segments[i]
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			if op := route.item.Operation(method); op != nil {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ValidateParameters checks the path and query parameters of a request
+func (v *Validator) ValidateParameters(op *OperationObject, pathParams map[string]string, query func(name string) (string, bool)) []Issue {
+	var issues []Issue
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			value, present = query(param.Name)
+		default:
+			continue
+		}
+		if !present || value == "" {
+			if param.Required {
+				issues = append(issues, Issue{Path: param.Name, Rule: "required"})
+			}
+			continue
+		}
+		v.validate(param.Schema, coerce(param.Schema, value), param.Name, &issues)
+	}
+	return issues
+}
+
+// RequestMediaType returns the media type of the request body matching
+// contentType, or nil when the operation doesn't accept it
+func RequestMediaType(op *OperationObject, contentType string) *MediaType {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return matchMediaType(op.RequestBody.Content, contentType)
+}
+
+// ResponseMediaType returns the documented body for status and contentType.
+// ok is false when the status itself is undocumented.
+func ResponseMediaType(op *OperationObject, status int, contentType string) (media *MediaType, ok bool) {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if response, ok = op.Responses["default"]; !ok {
+			return nil, false
+		}
+	}
+	return matchMediaType(response.Content, contentType), true
+}
+
+// matchMediaType looks contentType up in content, ignoring parameters such as charset
+func matchMediaType(content map[string]*MediaType, contentType string) *MediaType {
+	base, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	for documented, media := range content {
+		if documentedBase, _, err := mime.ParseMediaType(documented); err == nil && documentedBase == base {
+			return media
+		}
+	}
+	return nil
+}
+
+// ValidateJSON decodes body and checks it against schema. The error is
+// non-nil only when body isn't valid JSON.
+func (v *Validator) ValidateJSON(schema *Schema, body []byte) ([]Issue, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return []Issue{{Rule: "required"}}, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	v.validate(schema, value, "", &issues)
+	return issues, nil
+}
+
+// validate appends the ways value fails schema to issues
+func (v *Validator) validate(schema *Schema, value interface{}, path string, issues *[]Issue) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		v.validate(v.resolve(schema.Ref), value, path, issues)
+		return
+	}
+	for _, sub := range schema.AllOf {
+		v.validate(sub, value, path, issues)
+	}
+	if len(schema.AnyOf) > 0 {
+		var first []Issue
+		for i, sub := range schema.AnyOf {
+			var branch []Issue
+			v.validate(sub, value, path, &branch)
+			if len(branch) == 0 {
+				first = nil
+				break
+			}
+			if i == 0 {
+				first = branch
+			}
+		}
+		*issues = append(*issues, first...)
+	}
+
+	if types := schemaTypes(schema.Type); len(types) > 0 && !matchesAnyType(types, value) {
+		*issues = append(*issues, Issue{Path: path, Rule: "type", Param: strings.Join(types, "|")})
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		values := make([]string, 0, len(schema.Enum))
+		for _, allowed := range schema.Enum {
+			values = append(values, fmt.Sprint(allowed))
+		}
+		*issues = append(*issues, Issue{Path: path, Rule: "oneof", Param: strings.Join(values, " ")})
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			*issues = append(*issues, Issue{Path: path, Rule: "min", Param: strconv.Itoa(*schema.MinLength), String: true})
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			*issues = append(*issues, Issue{Path: path, Rule: "max", Param: strconv.Itoa(*schema.MaxLength), String: true})
+		}
+		if schema.Format != "" && !validFormat(schema.Format, value) {
+			if schema.Format == "email" {
+				*issues = append(*issues, Issue{Path: path, Rule: "email"})
+			} else {
+				*issues = append(*issues, Issue{Path: path, Rule: "format", Param: schema.Format})
+			}
+		}
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			break
+		}
+		if schema.Minimum != nil && number < *schema.Minimum {
+			*issues = append(*issues, Issue{Path: path, Rule: "min", Param: strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)})
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			*issues = append(*issues, Issue{Path: path, Rule: "max", Param: strconv.FormatFloat(*schema.Maximum, 'f', -1, 64)})
+		}
+	case []interface{}:
+		for i, item := range value {
+			v.validate(schema.Items, item, joinPath(path, strconv.Itoa(i)), issues)
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				*issues = append(*issues, Issue{Path: joinPath(path, name), Rule: "required"})
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property := value[name]
+			if propertySchema, ok := schema.Properties[name]; ok {
+				v.validate(propertySchema, property, joinPath(path, name), issues)
+			} else if schema.AdditionalProperties != nil {
+				v.validate(schema.AdditionalProperties, property, joinPath(path, name), issues)
+			}
+		}
+	}
+}
+
+// resolve looks up a local component reference
+func (v *Validator) resolve(ref string) *Schema {
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	if v.doc == nil {
+		return nil
+	}
+	return v.doc.Components.Schemas[name]
+}
+
+// schemaTypes normalizes the type keyword, which is a string or a list
+func schemaTypes(typ interface{}) []string {
+	switch typ := typ.(type) {
+	case string:
+		return []string{typ}
+	case []string:
+		return typ
+	case []interface{}:
+		types := make([]string, 0, len(typ))
+		for _, t := range typ {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesAnyType reports whether value is an instance of one of types
+func matchesAnyType(types []string, value interface{}) bool {
+	for _, typ := range types {
+		if matchesType(typ, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(typ string, value interface{}) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := number.Int64(); err == nil {
+			return true
+		}
+		f, err := number.Float64()
+		return err == nil && f == float64(int64(f))
+	}
+	return true
+}
+
+// inEnum compares value against the allowed values by their text form
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validFormat checks the string formats generated documents use
+func validFormat(format, value string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "e164":
+		return e164Pattern.MatchString(value)
+	}
+	return true
+}
+
+// coerce converts a parameter string into the JSON value its schema expects
+func coerce(schema *Schema, value string) interface{} {
+	if schema == nil {
+		return value
+	}
+	for _, typ := range schemaTypes(schema.Type) {
+		switch typ {
+		case "integer", "number":
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				return json.Number(value)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		}
+	}
+	return value
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,453 @@
+// Package realtime delivers per-user events to open client connections.
+// Events are appended to a short per-user Redis stream, so reconnecting
+// clients can resume from the last event they saw, and fanned out through
+// Redis pub/sub, so every replica can deliver them to its connections.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// bufferSize is how many live messages a connection may fall behind before
+// it is closed, on top of its resumed backlog
+const bufferSize = 64
+
+const (
+	streamPrefix      = "realtime:stream:"
+	channelPrefix     = "realtime:user:"
+	controlPrefix     = "realtime:control:"
+	connectionsPrefix = "realtime:connections:"
+)
+
+var (
+	// ErrTooManyConnections is returned by Connect when the user already has
+	// the maximum number of open connections
+	ErrTooManyConnections = errors.New("realtime: too many connections")
+	// ErrStopped is returned by Connect once the hub is stopped
+	ErrStopped = errors.New("realtime: hub stopped")
+	// ErrTokenExpired is a subscription's Err once the token it was opened
+	// with has expired
+	ErrTokenExpired = errors.New("realtime: token expired")
+	// ErrSessionsRevoked is a subscription's Err once the user signed out
+	// everywhere
+	ErrSessionsRevoked = errors.New("realtime: sessions revoked")
+)
+
+// Message is an event delivered to a user's connections
+type Message struct {
+	// ID is the event's stream ID, which clients send back to resume
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Config tunes a Hub
+type Config struct {
+	// HeartbeatInterval is how often connections are pinged; a connection
+	// missing three heartbeats no longer counts towards the cap
+	HeartbeatInterval time.Duration
+	// MaxConnections caps each user's open connections across replicas
+	MaxConnections int
+	// HistorySize and HistoryTTL bound the events kept for resuming
+	HistorySize int64
+	HistoryTTL  time.Duration
+}
+
+// Hub publishes events and delivers them to the connections open on this replica
+type Hub struct {
+	rdb    *redis.Client
+	cfg    Config
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	subs    map[uuid.UUID]map[*Subscription]struct{}
+	stopped bool
+	pubsub  *redis.PubSub
+	done    chan struct{}
+}
+
+// NewHub creates a hub. Publishing works right away; call Start to deliver
+// events to connections.
+func NewHub(rdb *redis.Client, cfg Config, logger *zap.Logger) *Hub {
+	return &Hub{
+		rdb:    rdb,
+		cfg:    cfg,
+		logger: logger,
+		subs:   map[uuid.UUID]map[*Subscription]struct{}{},
+		done:   make(chan struct{}),
+	}
+}
+
+// HeartbeatInterval is how often connections should be pinged
+func (h *Hub) HeartbeatInterval() time.Duration {
+	return h.cfg.HeartbeatInterval
+}
+
+// Subscribe forwards the bus events a user should see live to their connections
+func (h *Hub) Subscribe(bus *events.Bus) {
+	bus.SubscribeAsync(events.NameUserLoggedIn, func(ctx context.Context, event events.Event) error {
+		return h.Publish(ctx, event.(events.UserLoggedIn).User.ID, event)
+	})
+	bus.SubscribeAsync(events.NamePasswordChanged, func(ctx context.Context, event events.Event) error {
+		return h.Publish(ctx, event.(events.PasswordChanged).User.ID, event)
+	})
+	bus.SubscribeAsync(events.NameSessionsRevoked, func(ctx context.Context, event events.Event) error {
+		return h.Disconnect(ctx, event.(events.SessionsRevoked).UserID)
+	})
+}
+
+// publishScript appends an event to the user's stream and announces it with
+// its ID, so the order of the stream and the channel always agree
+var publishScript = redis.NewScript(`
+local id = redis.call("XADD", KEYS[1], "MAXLEN", "~", ARGV[1], "*", "type", ARGV[2], "data", ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+redis.call("PUBLISH", KEYS[2], id .. " " .. ARGV[2] .. " " .. ARGV[3])
+return id
+`)
+
+// Publish sends event to userID's connections on every replica
+func (h *Hub) Publish(ctx context.Context, userID uuid.UUID, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return publishScript.Run(ctx, h.rdb,
+		[]string{streamPrefix + userID.String(), channelPrefix + userID.String()},
+		h.cfg.HistorySize, event.EventName(), data, h.cfg.HistoryTTL.Milliseconds(),
+	).Err()
+}
+
+// Disconnect closes userID's connections on every replica
+func (h *Hub) Disconnect(ctx context.Context, userID uuid.UUID) error {
+	return h.rdb.Publish(ctx, controlPrefix+userID.String(), "disconnect").Err()
+}
+
+// Start listens for events and disconnects published by any replica
+func (h *Hub) Start() {
+	h.pubsub = h.rdb.PSubscribe(context.Background(), channelPrefix+"*", controlPrefix+"*")
+	go h.listen()
+}
+
+// Stop closes every connection's subscription and stops listening, or gives
+// up when ctx expires
+func (h *Hub) Stop(ctx context.Context) error {
+	h.mu.Lock()
+	h.stopped = true
+	subs := h.subs
+	h.subs = map[uuid.UUID]map[*Subscription]struct{}{}
+	h.mu.Unlock()
+	for _, userSubs := range subs {
+		for sub := range userSubs {
+			sub.end()
+		}
+	}
+
+	if h.pubsub == nil {
+		return nil
+	}
+	if err := h.pubsub.Close(); err != nil {
+		return err
+	}
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// listen delivers published events to this replica's subscriptions
+func (h *Hub) listen() {
+	defer close(h.done)
+	for msg := range h.pubsub.Channel() {
+		if id, ok := strings.CutPrefix(msg.Channel, controlPrefix); ok {
+			if userID, err := uuid.Parse(id); err == nil {
+				h.disconnect(userID)
+			}
+			continue
+		}
+		userID, err := uuid.Parse(strings.TrimPrefix(msg.Channel, channelPrefix))
+		if err != nil {
+			continue
+		}
+		parts := strings.SplitN(msg.Payload, " ", 3)
+		if len(parts) != 3 {
+			h.logger.Warn("Dropping malformed realtime message", zap.String("channel", msg.Channel))
+			continue
+		}
+		message := Message{ID: parts[0], Type: parts[1], Data: json.RawMessage(parts[2])}
+
+		h.mu.Lock()
+		for sub := range h.subs[userID] {
+			sub.deliver(message)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// disconnect ends userID's subscriptions on this replica
+func (h *Hub) disconnect(userID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[userID] {
+		sub.endWith(ErrSessionsRevoked)
+	}
+}
+
+// acquireScript takes one of the user's connection slots, first dropping the
+// slots of connections that stopped sending heartbeats
+var acquireScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[3], ARGV[4])
+redis.call("PEXPIRE", KEYS[1], ARGV[5])
+return 1
+`)
+
+// Connect opens a subscription to userID's events. With lastEventID, events
+// published after it that are still in the history are delivered first. The
+// subscription ends at expiresAt, when the token it was opened with expires,
+// unless expiresAt is zero.
+func (h *Hub) Connect(ctx context.Context, userID uuid.UUID, lastEventID string, expiresAt time.Time) (*Subscription, error) {
+	sub := &Subscription{
+		hub:      h,
+		userID:   userID,
+		id:       uuid.NewString(),
+		messages: make(chan Message, h.cfg.HistorySize+bufferSize),
+	}
+	now := time.Now()
+	acquired, err := acquireScript.Run(ctx, h.rdb, []string{sub.slotKey()},
+		now.UnixMilli(), h.cfg.MaxConnections, sub.slotExpiry(now), sub.id, h.slotTTL().Milliseconds(),
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	if acquired == 0 {
+		return nil, ErrTooManyConnections
+	}
+
+	// Register before reading the history, so no event falls in between;
+	// live events are held back until the history is queued
+	h.mu.Lock()
+	if h.stopped {
+		h.mu.Unlock()
+		sub.release()
+		return nil, ErrStopped
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*Subscription]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	var backlog []Message
+	if _, _, ok := parseID(lastEventID); ok {
+		sub.last = strings.Clone(lastEventID)
+		entries, err := h.rdb.XRangeN(ctx, streamPrefix+userID.String(), sub.last, "+", h.cfg.HistorySize+1).Result()
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+		for _, entry := range entries {
+			eventType, _ := entry.Values["type"].(string)
+			data, _ := entry.Values["data"].(string)
+			backlog = append(backlog, Message{ID: entry.ID, Type: eventType, Data: json.RawMessage(data)})
+		}
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	for _, msg := range append(backlog, sub.pending...) {
+		sub.send(msg)
+	}
+	sub.pending = nil
+	sub.ready = true
+	if !expiresAt.IsZero() {
+		sub.expiry = time.AfterFunc(time.Until(expiresAt), func() { sub.endWith(ErrTokenExpired) })
+	}
+	return sub, nil
+}
+
+// slotTTL is how long a connection slot lasts without a heartbeat
+func (h *Hub) slotTTL() time.Duration {
+	return 3 * h.cfg.HeartbeatInterval
+}
+
+// remove forgets sub, once its connection has closed
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subs, ok := h.subs[sub.userID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subs, sub.userID)
+		}
+	}
+}
+
+// Subscription is one connection's feed of a user's events
+type Subscription struct {
+	hub      *Hub
+	userID   uuid.UUID
+	id       string
+	messages chan Message
+
+	mu      sync.Mutex
+	ready   bool
+	pending []Message
+	// last is the ID of the last message queued, so a resumed event isn't
+	// delivered twice
+	last  string
+	ended bool
+	err   error
+	// expiry ends the subscription when its token expires
+	expiry *time.Timer
+
+	closeOnce sync.Once
+}
+
+// Messages returns the subscription's events. The channel is closed when the
+// connection falls too far behind or the hub stops; the client should
+// reconnect and resume from the last event it received. It is also closed
+// when the token expires or the user's sessions are revoked, as Err reports.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Err returns why the subscription ended: ErrTokenExpired,
+// ErrSessionsRevoked, or nil when the client may simply reconnect
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Heartbeat keeps the connection's slot counted towards the cap
+func (s *Subscription) Heartbeat(ctx context.Context) error {
+	now := time.Now()
+	pipe := s.hub.rdb.TxPipeline()
+	pipe.ZAddXX(ctx, s.slotKey(), redis.Z{Score: float64(s.slotExpiry(now)), Member: s.id})
+	pipe.PExpire(ctx, s.slotKey(), s.hub.slotTTL())
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Close ends the subscription and frees its connection slot
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		if s.expiry != nil {
+			s.expiry.Stop()
+		}
+		s.mu.Unlock()
+		s.hub.remove(s)
+		s.end()
+		s.release()
+	})
+}
+
+// deliver queues a live message, or holds it back until the history is queued
+func (s *Subscription) deliver(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ready {
+		s.pending = append(s.pending, msg)
+		return
+	}
+	s.send(msg)
+}
+
+// send queues msg unless it was already queued. A connection too slow to
+// keep up is ended rather than allowed to block delivery to others.
+func (s *Subscription) send(msg Message) {
+	if s.ended || (s.last != "" && !after(msg.ID, s.last)) {
+		return
+	}
+	select {
+	case s.messages <- msg:
+		s.last = msg.ID
+	default:
+		s.hub.logger.Warn("Closing slow realtime connection", zap.String("user_id", s.userID.String()))
+		s.endLocked()
+	}
+}
+
+func (s *Subscription) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endLocked()
+}
+
+// endWith ends the subscription, recording err as the reason
+func (s *Subscription) endWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.err = err
+	}
+	s.endLocked()
+}
+
+func (s *Subscription) endLocked() {
+	if !s.ended {
+		s.ended = true
+		close(s.messages)
+	}
+}
+
+// release frees the connection slot
+func (s *Subscription) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.hub.rdb.ZRem(ctx, s.slotKey(), s.id).Err(); err != nil {
+		s.hub.logger.Warn("Failed to release realtime connection slot", zap.Error(err))
+	}
+}
+
+func (s *Subscription) slotKey() string {
+	return connectionsPrefix + s.userID.String()
+}
+
+func (s *Subscription) slotExpiry(now time.Time) int64 {
+	return now.Add(s.hub.slotTTL()).UnixMilli()
+}
+
+// parseID splits a stream ID of the form <milliseconds>-<sequence>
+func parseID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// after reports whether stream ID a comes after b
+func after(a, b string) bool {
+	aMs, aSeq, aOK := parseID(a)
+	bMs, bSeq, bOK := parseID(b)
+	if !aOK || !bOK {
+		return true
+	}
+	return aMs > bMs || (aMs == bMs && aSeq > bSeq)
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
+)
+
+// History stores the runs of scheduled jobs
+type History struct {
+	db *gorm.DB
+}
+
+// NewHistory creates a history backed by the scheduled_runs table
+func NewHistory(db *gorm.DB) *History {
+	return &History{db: db}
+}
+
+// Record stores a finished run
+func (h *History) Record(ctx context.Context, run *models.ScheduledRun) error {
+	return h.db.WithContext(ctx).Create(run).Error
+}
+
+// Prune deletes runs that started more than olderThan ago and returns how many
+func (h *History) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := h.db.WithContext(ctx).Where("started_at < ?", time.Now().Add(-olderThan)).Delete(&models.ScheduledRun{})
+	return result.RowsAffected, result.Error
+}
+
+// List returns runs, newest first, optionally filtered by job and status
+func (h *History) List(ctx context.Context, job, status string, limit, offset int) ([]models.ScheduledRun, error) {
+	query := h.db.WithContext(ctx).Order("started_at DESC").Limit(limit).Offset(offset)
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var runs []models.ScheduledRun
+	err := query.Find(&runs).Error
+	return runs, err
+}
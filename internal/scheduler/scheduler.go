@@ -0,0 +1,214 @@
+// Package scheduler runs maintenance jobs on cron schedules. Every replica
+// runs a scheduler; a Redis lock per run makes sure each scheduled run
+// happens on one replica only, and each run is recorded in scheduled_runs.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// claimTTL is how long a claimed run is remembered, so a replica whose clock
+// lags behind can't run it again after the lock is released
+const claimTTL = 24 * time.Hour
+
+// Func is the body of a scheduled job. ctx expires after the job's timeout.
+type Func func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	timeout  time.Duration
+	fn       Func
+}
+
+// Scheduler runs registered jobs on their schedules
+type Scheduler struct {
+	rdb      *redis.Client
+	history  *History
+	logger   *zap.Logger
+	instance string
+	entries  []*entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler that records runs in history
+func New(rdb *redis.Client, history *History, logger *zap.Logger) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		rdb:      rdb,
+		history:  history,
+		logger:   logger,
+		instance: hostname + ":" + strconv.Itoa(os.Getpid()),
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Register adds a job running fn on spec, a standard 5 field cron expression
+// or a descriptor such as @daily or @every 1h. Register jobs before Start.
+func (s *Scheduler) Register(name, spec string, timeout time.Duration, fn Func) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("scheduler: job %s: %w", name, err)
+	}
+	s.entries = append(s.entries, &entry{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		timeout:  timeout,
+		fn:       fn,
+	})
+	return nil
+}
+
+// Start runs every registered job on its schedule in the background
+func (s *Scheduler) Start() {
+	for _, e := range s.entries {
+		s.logger.Info("Scheduled job", zap.String("job", e.name), zap.String("schedule", e.spec))
+		s.wg.Add(1)
+		go s.run(e)
+	}
+}
+
+// Stop stops scheduling and waits for running jobs. If ctx expires first,
+// running jobs are cancelled.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// run triggers e at each of its scheduled times. Runs of a job never overlap
+// on one replica; times that pass while a run is in progress are skipped.
+func (s *Scheduler) run(e *entry) {
+	defer s.wg.Done()
+	for {
+		next := e.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(e, next)
+	}
+}
+
+// claimScript marks the run in KEYS[2] as claimed and takes the job's lock in
+// KEYS[1], unless the job is still running elsewhere or the run was already
+// claimed
+var claimScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+if not redis.call("SET", KEYS[2], ARGV[1], "NX", "PX", ARGV[3]) then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// releaseScript deletes the lock if it is still held with the token in ARGV[1]
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// trigger runs e for the time it was scheduled at, if this replica claims it
+func (s *Scheduler) trigger(e *entry, scheduledAt time.Time) {
+	logger := s.logger.With(zap.String("job", e.name))
+	token := uuid.NewString()
+	lock := "scheduler:lock:" + e.name
+	claim := "scheduler:run:" + e.name + ":" + strconv.FormatInt(scheduledAt.Unix(), 10)
+	// Hold the lock a little longer than the job may run
+	lockTTL := e.timeout + time.Minute
+
+	claimed, err := claimScript.Run(s.ctx, s.rdb, []string{lock, claim},
+		token, lockTTL.Milliseconds(), claimTTL.Milliseconds(),
+	).Int()
+	if err != nil {
+		logger.Error("Failed to claim scheduled run", zap.Error(err))
+		return
+	}
+	if claimed == 0 {
+		logger.Debug("Scheduled run claimed by another instance")
+		return
+	}
+	defer func() {
+		if err := releaseScript.Run(context.Background(), s.rdb, []string{lock}, token).Err(); err != nil {
+			logger.Error("Failed to release scheduler lock", zap.Error(err))
+		}
+	}()
+
+	run := &models.ScheduledRun{
+		Job:       e.name,
+		Instance:  s.instance,
+		Status:    models.RunStatusSucceeded,
+		StartedAt: time.Now(),
+	}
+	runErr := s.execute(e)
+	run.FinishedAt = time.Now()
+	run.DurationMS = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	if runErr != nil {
+		run.Status = models.RunStatusFailed
+		run.Error = runErr.Error()
+		logger.Error("Scheduled job failed", zap.Int64("duration_ms", run.DurationMS), zap.Error(runErr))
+	} else {
+		logger.Info("Scheduled job finished", zap.Int64("duration_ms", run.DurationMS))
+	}
+	metrics.ScheduledRunsTotal.WithLabelValues(e.name, run.Status).Inc()
+	metrics.ScheduledRunDuration.WithLabelValues(e.name).Observe(run.FinishedAt.Sub(run.StartedAt).Seconds())
+
+	if err := s.history.Record(context.Background(), run); err != nil {
+		logger.Error("Failed to record scheduled run", zap.Error(err))
+	}
+}
+
+// execute calls the job's function with its timeout
+func (s *Scheduler) execute(e *entry) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, e.timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduler: job panicked: %v", r)
+		}
+	}()
+	return e.fn(ctx)
+}
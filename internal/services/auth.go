@@ -1,12 +1,18 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -17,33 +23,50 @@ type AuthService struct {
 	redisService *RedisService
 	emailService *EmailService
 	otpService   *OtpService
+	bus          *events.Bus
+	loginAlerts  *LoginAlertService
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(db *gorm.DB, jwtService *JWTService, redisService *RedisService, emailService *EmailService, otpService *OtpService) *AuthService {
+// NewAuthService creates a new auth service. Sign-ins are not checked for new
+// devices when loginAlerts is nil.
+func NewAuthService(db *gorm.DB, jwtService *JWTService, redisService *RedisService, emailService *EmailService, otpService *OtpService, bus *events.Bus, loginAlerts *LoginAlertService) *AuthService {
 	return &AuthService{
 		db:           db,
 		jwtService:   jwtService,
 		redisService: redisService,
 		emailService: emailService,
 		otpService:   otpService,
+		bus:          bus,
+		loginAlerts:  loginAlerts,
 	}
 }
 
 // Register creates a new user account
-func (a *AuthService) Register(input models.RegisterInput) (*models.AuthResponse, error) {
+func (a *AuthService) Register(ctx context.Context, input models.RegisterInput) (*models.AuthResponse, error) {
 	// Check if user already exists
 	var existingUser models.User
-	if err := a.db.Where("email = ? OR username = ?", input.Email, input.Username).First(&existingUser).Error; err == nil {
-		return nil, errors.New("user already exists")
+	err := a.db.WithContext(ctx).Where("email = ? OR username = ?", input.Email, input.Username).First(&existingUser).Error
+	if err == nil {
+		return nil, apperrors.ErrUserExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
 
-	// Hash password
+	// Hash password, unless the request has already been abandoned
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	hashedPassword, err := utils.Hash(input.Password)
 	if err != nil {
 		return nil, err
 	}
 
+	// Remember the request's language unless another was chosen
+	if input.Locale == "" {
+		input.Locale = i18n.FromContext(ctx)
+	}
+
 	// Create user
 	user := models.User{
 		Email:     input.Email,
@@ -51,63 +74,229 @@ func (a *AuthService) Register(input models.RegisterInput) (*models.AuthResponse
 		Password:  hashedPassword,
 		FirstName: input.FirstName,
 		LastName:  input.LastName,
+		Phone:     input.Phone,
+		Locale:    input.Locale,
 		IsActive:  true,
 	}
 
-	if err := a.db.Create(&user).Error; err != nil {
+	err = transaction(ctx, a.db, a.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return emit(events.UserRegistered{User: user.ToResponse()})
+	})
+	if err != nil {
 		return nil, err
 	}
+	metrics.AuthRegistrationsTotal.Inc()
 
-	// Generate access token (24 hours)
-	accessToken, err := a.jwtService.GenerateToken(&user, 24*time.Hour)
+	return a.issueTokens(ctx, &user)
+}
+
+// Login authenticates a user signing in from client
+func (a *AuthService) Login(ctx context.Context, input models.LoginInput, client models.ClientInfo) (*models.AuthResponse, error) {
+	// Find user
+	var user models.User
+	if err := a.db.WithContext(ctx).Where("email = ?", input.Email).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrInvalidCredentials
+	}
+
+	// Check if user is active
+	if !user.IsActive {
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrAccountInactive
+	}
+
+	// Verify password, unless the request has already been abandoned
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	valid, err := utils.Verify(input.Password, user.Password)
 	if err != nil {
 		return nil, err
 	}
+	if !valid {
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrInvalidCredentials
+	}
 
-	// Generate refresh token (7 days)
-	refreshToken, err := a.jwtService.GenerateToken(&user, 7*24*time.Hour)
+	check, err := a.checkLogin(ctx, &user, client, events.LoginMethodPassword)
 	if err != nil {
 		return nil, err
 	}
-
-	return &models.AuthResponse{
-		User:         user.ToResponse(),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-	}, nil
+	resp, err := a.issueTokens(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+	metrics.AuthLoginsTotal.WithLabelValues("success").Inc()
+	a.recordLogin(ctx, &user, check)
+	a.bus.Publish(ctx, events.UserLoggedIn{User: user.ToResponse(), Method: events.LoginMethodPassword})
+	return resp, nil
 }
 
-// Login authenticates a user
-func (a *AuthService) Login(input models.LoginInput) (*models.AuthResponse, error) {
-	// Find user
-	var user models.User
-	if err := a.db.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		return nil, errors.New("invalid credentials")
+// RequestOTP sends a one-time sign-in code through the chosen channel. Unknown
+// and inactive accounts, accounts the channel can't reach and used up quotas
+// all get the same response, so the endpoint can't be used to discover
+// accounts; failures are only logged.
+func (a *AuthService) RequestOTP(ctx context.Context, input models.OTPRequestInput, client models.ClientInfo) (*models.OTPRequestResponse, error) {
+	channel, err := a.otpService.Channel(input.Channel)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.OTPRequestResponse{
+		Channel:   channel.Name(),
+		ExpiresIn: int(a.otpService.TTL().Seconds()),
 	}
 
-	// Check if user is active
+	var user models.User
+	if err := a.db.WithContext(ctx).Where("email = ?", input.Email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, nil
+		}
+		return nil, err
+	}
 	if !user.IsActive {
-		return nil, errors.New("account is inactive")
+		return resp, nil
+	}
+	if err := a.otpService.Send(ctx, &user, channel, client); err != nil {
+		if appErr, ok := apperrors.As(err); ok && (appErr.Code == apperrors.CodeInvalidInput || appErr.Code == apperrors.CodeTooManyRequests) {
+			utils.LoggerFromContext(ctx).Warn("OTP not sent",
+				zap.String("user_id", user.ID.String()),
+				zap.String("channel", channel.Name()),
+				zap.Error(err),
+			)
+			return resp, nil
+		}
+		return nil, err
 	}
+	return resp, nil
+}
 
-	// Verify password
-	valid, err := utils.Verify(input.Password, user.Password)
+// RequestPhoneVerification texts a code to the user's phone number. The sms
+// channel only sends sign-in codes to numbers confirmed with VerifyPhone.
+func (a *AuthService) RequestPhoneVerification(ctx context.Context, user *models.User, client models.ClientInfo) error {
+	if user.PhoneVerifiedAt != nil {
+		return apperrors.New(apperrors.CodeConflict, "Phone number is already verified")
+	}
+	return a.otpService.SendPhoneVerification(ctx, user, client)
+}
+
+// VerifyPhone marks the user's phone number verified with a code from
+// RequestPhoneVerification
+func (a *AuthService) VerifyPhone(ctx context.Context, user *models.User, code string) (*models.UserResponse, error) {
+	valid, err := a.otpService.VerifyPhone(ctx, user, code)
 	if err != nil {
 		return nil, err
 	}
 	if !valid {
-		return nil, errors.New("invalid credentials")
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "Invalid or expired code")
+	}
+	now := time.Now()
+	if err := a.db.WithContext(ctx).Model(user).Update("phone_verified_at", now).Error; err != nil {
+		return nil, err
+	}
+	user.PhoneVerifiedAt = &now
+	resp := user.ToResponse()
+	return &resp, nil
+}
+
+// VerifyOTP signs a user in from client with a code from RequestOTP or a
+// step-up challenge
+func (a *AuthService) VerifyOTP(ctx context.Context, input models.OTPVerifyInput, client models.ClientInfo) (*models.AuthResponse, error) {
+	var user models.User
+	if err := a.db.WithContext(ctx).Where("email = ?", input.Email).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrInvalidCredentials
 	}
+	valid, err := a.otpService.Verify(ctx, user.ID, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		metrics.AuthLoginsTotal.WithLabelValues("failure").Inc()
+		return nil, apperrors.ErrAccountInactive
+	}
+
+	check, err := a.checkLogin(ctx, &user, client, events.LoginMethodOTP)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.issueTokens(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+	metrics.AuthLoginsTotal.WithLabelValues("success").Inc()
+	a.recordLogin(ctx, &user, check)
+	a.bus.Publish(ctx, events.UserLoggedIn{User: user.ToResponse(), Method: events.LoginMethodOTP})
+	return resp, nil
+}
+
+// checkLogin compares a sign-in with the user's known devices. A password
+// sign-in flagged as impossible travel fails with AUTH_STEP_UP_REQUIRED when
+// step-up is enabled, after a one-time code is sent to finish it with.
+func (a *AuthService) checkLogin(ctx context.Context, user *models.User, client models.ClientInfo, method string) (*LoginCheck, error) {
+	if a.loginAlerts == nil {
+		return nil, nil
+	}
+	check, err := a.loginAlerts.Check(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	if !check.ImpossibleTravel || !a.loginAlerts.StepUp() || method != events.LoginMethodPassword {
+		return check, nil
+	}
+	channel, err := a.otpService.Channel("")
+	if err != nil {
+		return nil, err
+	}
+	if err := a.otpService.Send(ctx, user, channel, client); err != nil {
+		return nil, err
+	}
+	metrics.AuthLoginsTotal.WithLabelValues("step_up").Inc()
+	return nil, apperrors.ErrStepUpRequired
+}
+
+// recordLogin remembers the device of a completed sign-in and alerts the user
+// if it was flagged. The sign-in succeeds even if this fails.
+func (a *AuthService) recordLogin(ctx context.Context, user *models.User, check *LoginCheck) {
+	if check == nil {
+		return
+	}
+	if err := a.loginAlerts.Record(ctx, user, check); err != nil {
+		utils.LoggerFromContext(ctx).Error("Failed to record sign-in device", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+}
+
+// RevokeSessions signs the user out everywhere with the token of a new
+// sign-in alert
+func (a *AuthService) RevokeSessions(ctx context.Context, token string) error {
+	if a.loginAlerts == nil {
+		return apperrors.New(apperrors.CodeAuthInvalidToken, "Invalid or expired link")
+	}
+	return a.loginAlerts.RevokeSessions(ctx, token)
+}
 
+// issueTokens creates the access and refresh tokens for user
+func (a *AuthService) issueTokens(ctx context.Context, user *models.User) (*models.AuthResponse, error) {
 	// Generate access token (24 hours)
-	accessToken, err := a.jwtService.GenerateToken(&user, 24*time.Hour)
+	accessToken, err := a.jwtService.GenerateToken(ctx, user, 24*time.Hour)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate refresh token (7 days)
-	refreshToken, err := a.jwtService.GenerateToken(&user, 7*24*time.Hour)
+	refreshToken, err := a.jwtService.GenerateToken(ctx, user, 7*24*time.Hour)
 	if err != nil {
 		return nil, err
 	}
@@ -1,32 +1,188 @@
 package services
 
 import (
-	"github.com/go-mail/mail"
-	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/mailer"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
 )
 
+// EmailService queues emails in the outbox table. The EmailOutboxWorker
+// delivers them through the configured mailer outside the request path.
 type EmailService struct {
-	mailer *mail.Dialer
-	From   string
+	db       *gorm.DB
+	mailer   mailer.Mailer
+	renderer *email.Renderer
+	From     string
 }
 
-func NewEmailService(emailConfig config.SMTPConfig) *EmailService {
-	mailer := mail.NewDialer(emailConfig.Host, emailConfig.Port, emailConfig.Username, emailConfig.Password)
+// NewEmailService creates a new email service that renders templated emails
+// with renderer and sends them from the from address through m
+func NewEmailService(db *gorm.DB, m mailer.Mailer, from string, renderer *email.Renderer) *EmailService {
 	return &EmailService{
-		mailer: mailer,
-		From:   emailConfig.From,
+		db:       db,
+		mailer:   m,
+		renderer: renderer,
+		From:     from,
+	}
+}
+
+// WithTx returns a copy of the service that queues emails in tx, so they are
+// only sent if the surrounding transaction commits
+func (e *EmailService) WithTx(tx *gorm.DB) *EmailService {
+	clone := *e
+	clone.db = tx
+	return &clone
+}
+
+// Ping checks that the mail server is reachable, for mailers that have one
+func (e *EmailService) Ping(ctx context.Context) error {
+	if pinger, ok := e.mailer.(mailer.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// SendEmail queues a plain text email
+func (e *EmailService) SendEmail(ctx context.Context, to, subject, body string) error {
+	return e.queue(ctx, &models.OutboxEmail{To: to, Subject: subject, Text: body})
+}
+
+// Render renders the email template name in the locale carried by ctx
+func (e *EmailService) Render(ctx context.Context, name string, data interface{}) (*email.Rendered, error) {
+	return e.renderer.Render(i18n.FromContext(ctx), name, data)
+}
+
+// SendRendered queues a rendered email, sent as multipart/alternative text and HTML
+func (e *EmailService) SendRendered(ctx context.Context, to string, rendered *email.Rendered) error {
+	return e.queue(ctx, &models.OutboxEmail{
+		To:      to,
+		Subject: rendered.Subject,
+		Text:    rendered.Text,
+		HTML:    rendered.HTML,
+	})
+}
+
+// SendVerifyEmail sends the email address verification link
+func (e *EmailService) SendVerifyEmail(ctx context.Context, to string, data email.VerifyEmailData) error {
+	return e.sendTemplate(ctx, to, email.TemplateVerifyEmail, data)
+}
+
+// SendResetPassword sends the password reset link
+func (e *EmailService) SendResetPassword(ctx context.Context, to string, data email.ResetPasswordData) error {
+	return e.sendTemplate(ctx, to, email.TemplateResetPassword, data)
+}
+
+// SendOTP sends a one-time password
+func (e *EmailService) SendOTP(ctx context.Context, to string, data email.OTPData) error {
+	return e.sendTemplate(ctx, to, email.TemplateOTP, data)
+}
+
+// SendNewLoginAlert warns the user about a sign-in from a new device
+func (e *EmailService) SendNewLoginAlert(ctx context.Context, to string, data email.NewLoginData) error {
+	return e.sendTemplate(ctx, to, email.TemplateNewLogin, data)
+}
+
+// SendInvitation invites someone to create an account
+func (e *EmailService) SendInvitation(ctx context.Context, to string, data email.InvitationData) error {
+	return e.sendTemplate(ctx, to, email.TemplateInvitation, data)
+}
+
+// sendTemplate renders and queues the email template name
+func (e *EmailService) sendTemplate(ctx context.Context, to, name string, data interface{}) error {
+	rendered, err := e.Render(ctx, name, data)
+	if err != nil {
+		return err
+	}
+	return e.SendRendered(ctx, to, rendered)
+}
+
+// queue stores msg in the outbox
+func (e *EmailService) queue(ctx context.Context, msg *models.OutboxEmail) error {
+	return e.db.WithContext(ctx).Create(msg).Error
+}
+
+// ListOutbox returns outbox emails, newest first, optionally filtered by status
+func (e *EmailService) ListOutbox(ctx context.Context, status string, limit, offset int) ([]models.OutboxEmail, error) {
+	query := e.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
 	}
+	var emails []models.OutboxEmail
+	err := query.Find(&emails).Error
+	return emails, err
 }
 
-func (e *EmailService) SendEmail(to, subject, body string) error {
-	m := mail.NewMessage()
-	m.SetHeader("From", e.From)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+// GetOutboxEmail returns one outbox email
+func (e *EmailService) GetOutboxEmail(ctx context.Context, id uuid.UUID) (*models.OutboxEmail, error) {
+	var msg models.OutboxEmail
+	if err := e.db.WithContext(ctx).Where("id = ?", id).First(&msg).Error; err != nil {
+		return nil, outboxNotFound(err)
+	}
+	return &msg, nil
+}
+
+// RetryOutboxEmail schedules a pending or dead email for immediate delivery
+// with a fresh set of attempts
+func (e *EmailService) RetryOutboxEmail(ctx context.Context, id uuid.UUID) (*models.OutboxEmail, error) {
+	var msg models.OutboxEmail
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&msg).Error; err != nil {
+			return outboxNotFound(err)
+		}
+		if msg.Status == models.EmailStatusSent {
+			return apperrors.New(apperrors.CodeConflict, "Email has already been sent")
+		}
+		return tx.Model(&msg).Updates(map[string]interface{}{
+			"status":          models.EmailStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"locked_until":    nil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// PruneOutbox deletes sent emails older than olderThan and returns how many
+func (e *EmailService) PruneOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := e.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", models.EmailStatusSent, time.Now().Add(-olderThan)).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
+
+// outboxNotFound maps a missing outbox row to a NOT_FOUND error
+func outboxNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "Email not found", err)
+	}
+	return err
+}
 
-	if err := e.mailer.DialAndSend(m); err != nil {
+// deliver sends an outbox email through the mailer
+func (e *EmailService) deliver(ctx context.Context, msg *models.OutboxEmail) error {
+	err := e.mailer.Send(ctx, &mailer.Message{
+		From:    e.From,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+	})
+	if err != nil {
+		metrics.EmailsFailedTotal.Inc()
 		return err
 	}
+	metrics.EmailsSentTotal.Inc()
 	return nil
 }
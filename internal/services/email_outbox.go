@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/mailer"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// outboxLease is how long a claimed email is hidden from other workers. A
+// worker that dies mid-batch releases its emails when the lease runs out.
+const outboxLease = 5 * time.Minute
+
+// EmailOutboxWorker delivers queued emails, retrying failures with
+// exponential backoff until they are sent or dead-lettered
+type EmailOutboxWorker struct {
+	db           *gorm.DB
+	emailService *EmailService
+	cfg          config.EmailConfig
+	logger       *zap.Logger
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewEmailOutboxWorker creates a worker that delivers through emailService
+func NewEmailOutboxWorker(db *gorm.DB, emailService *EmailService, cfg config.EmailConfig, logger *zap.Logger) *EmailOutboxWorker {
+	return &EmailOutboxWorker{
+		db:           db,
+		emailService: emailService,
+		cfg:          cfg,
+		logger:       logger,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start polls the outbox in the background until Stop is called
+func (w *EmailOutboxWorker) Start() {
+	go w.run()
+}
+
+// Stop waits for the batch in progress to finish, or for ctx to expire
+func (w *EmailOutboxWorker) Stop(ctx context.Context) error {
+	w.once.Do(func() { close(w.stop) })
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *EmailOutboxWorker) run() {
+	defer close(w.done)
+	defer w.emailService.mailer.Close()
+
+	ticker := time.NewTicker(w.cfg.OutboxPollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep draining while full batches come back
+		for w.processBatch() == w.cfg.OutboxBatchSize {
+			if w.stopped() {
+				return
+			}
+		}
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processBatch delivers one batch of due emails and returns how many it claimed
+func (w *EmailOutboxWorker) processBatch() int {
+	ctx := context.Background()
+	emails, err := w.claim(ctx)
+	if err != nil {
+		w.logger.Error("Failed to claim outbox emails", zap.Error(err))
+		return 0
+	}
+	if len(emails) == 0 {
+		// Don't hold connections open while the outbox is empty
+		if idleCloser, ok := w.emailService.mailer.(mailer.IdleCloser); ok {
+			idleCloser.CloseIdle(w.cfg.OutboxPollInterval)
+		}
+		return 0
+	}
+
+	// Send up to OutboxConcurrency emails at once; the rest wait their turn
+	sem := make(chan struct{}, w.cfg.OutboxConcurrency)
+	var wg sync.WaitGroup
+	for i := range emails {
+		sem <- struct{}{}
+		if w.stopped() {
+			<-sem
+			w.release(ctx, emails[i:])
+			break
+		}
+		wg.Add(1)
+		go func(msg *models.OutboxEmail) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.attempt(ctx, msg)
+		}(&emails[i])
+	}
+	wg.Wait()
+	return len(emails)
+}
+
+// claim leases due pending emails so concurrent workers skip them
+func (w *EmailOutboxWorker) claim(ctx context.Context) ([]models.OutboxEmail, error) {
+	now := time.Now()
+	var emails []models.OutboxEmail
+	err := w.db.WithContext(ctx).Raw(`
+		UPDATE email_outbox SET locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(outboxLease), now, models.EmailStatusPending, now, now, w.cfg.OutboxBatchSize,
+	).Scan(&emails).Error
+	return emails, err
+}
+
+// attempt sends msg and records the outcome
+func (w *EmailOutboxWorker) attempt(ctx context.Context, msg *models.OutboxEmail) {
+	attempts := msg.Attempts + 1
+	sendErr := w.emailService.deliver(ctx, msg)
+
+	updates := map[string]interface{}{
+		"attempts":     attempts,
+		"locked_until": nil,
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.EmailStatusSent
+		updates["sent_at"] = time.Now()
+		updates["last_error"] = ""
+	case attempts >= w.cfg.MaxAttempts:
+		updates["status"] = models.EmailStatusDead
+		updates["last_error"] = sendErr.Error()
+		metrics.EmailsDeadLetteredTotal.Inc()
+		w.logger.Warn("Email moved to dead letter",
+			zap.String("email_id", msg.ID.String()),
+			zap.Int("attempts", attempts),
+			zap.Error(sendErr),
+		)
+	default:
+		delay := w.backoff(attempts)
+		updates["last_error"] = sendErr.Error()
+		updates["next_attempt_at"] = time.Now().Add(delay)
+		w.logger.Info("Email delivery failed, will retry",
+			zap.String("email_id", msg.ID.String()),
+			zap.Int("attempts", attempts),
+			zap.Duration("retry_in", delay),
+			zap.Error(sendErr),
+		)
+	}
+
+	if err := w.db.WithContext(ctx).Model(msg).Updates(updates).Error; err != nil {
+		w.logger.Error("Failed to record email delivery", zap.String("email_id", msg.ID.String()), zap.Error(err))
+	}
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled
+// per failed attempt, capped at the max delay, with up to 20% jitter
+func (w *EmailOutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryBaseDelay
+	for i := 1; i < attempts && delay < w.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > w.cfg.RetryMaxDelay {
+		delay = w.cfg.RetryMaxDelay
+	}
+	if jitter := int64(delay / 5); jitter > 0 {
+		delay += time.Duration(rand.Int64N(jitter))
+	}
+	return delay
+}
+
+// release hands unsent claimed emails back to the outbox on shutdown
+func (w *EmailOutboxWorker) release(ctx context.Context, emails []models.OutboxEmail) {
+	ids := make([]interface{}, len(emails))
+	for i, msg := range emails {
+		ids[i] = msg.ID
+	}
+	if err := w.db.WithContext(ctx).Model(&models.OutboxEmail{}).Where("id IN ?", ids).Update("locked_until", nil).Error; err != nil {
+		w.logger.Error("Failed to release outbox emails", zap.Error(err))
+	}
+}
+
+func (w *EmailOutboxWorker) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
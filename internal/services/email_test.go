@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/mailer"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a database that builds statements without running them,
+// passing every created value and every update to the record functions
+func dryRunDB(t *testing.T, created func(interface{}), updated func(interface{})) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = errors.Join(
+		db.Callback().Create().After("gorm:create").Register("test:created", func(tx *gorm.DB) {
+			created(tx.Statement.Dest)
+		}),
+		db.Callback().Update().After("gorm:update").Register("test:updated", func(tx *gorm.DB) {
+			updated(tx.Statement.Dest)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newMemoryEmailService returns an email service delivering through the memory transport
+func newMemoryEmailService(t *testing.T, db *gorm.DB) (*EmailService, *mailer.Memory) {
+	t.Helper()
+	renderer, err := email.NewRenderer("Acme", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mailer.New(config.EmailConfig{Transport: mailer.TransportMemory}, config.SMTPConfig{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory, ok := m.(*mailer.Memory)
+	if !ok {
+		t.Fatalf("memory transport created a %T", m)
+	}
+	return NewEmailService(db, memory, "noreply@example.com", renderer), memory
+}
+
+func TestEmailOutboxDeliversThroughMemory(t *testing.T) {
+	var queued []*models.OutboxEmail
+	var updates []map[string]interface{}
+	db := dryRunDB(t,
+		func(dest interface{}) {
+			if msg, ok := dest.(*models.OutboxEmail); ok {
+				queued = append(queued, msg)
+			}
+		},
+		func(dest interface{}) {
+			if values, ok := dest.(map[string]interface{}); ok {
+				updates = append(updates, values)
+			}
+		},
+	)
+	emailService, memory := newMemoryEmailService(t, db)
+
+	// Queueing only writes the outbox; nothing is sent yet
+	ctx := i18n.WithLocale(context.Background(), "es")
+	if err := emailService.SendOTP(ctx, "ada@example.com", email.OTPData{Code: "123456", ExpiresIn: 5 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 1 {
+		t.Fatalf("queued %d emails, want 1", len(queued))
+	}
+	if len(memory.Messages()) != 0 {
+		t.Fatal("email was sent before the outbox worker ran")
+	}
+	msg := queued[0]
+	if msg.To != "ada@example.com" || !strings.Contains(msg.Text, "Tu código OTP es: 123456") || msg.HTML == "" {
+		t.Fatalf("queued email = %+v, want the Spanish OTP email", msg)
+	}
+
+	worker := NewEmailOutboxWorker(db, emailService, config.EmailConfig{MaxAttempts: 3}, zap.NewNop())
+	worker.attempt(context.Background(), msg)
+
+	sent, ok := memory.Last()
+	if !ok {
+		t.Fatal("the worker sent nothing")
+	}
+	if sent.From != "noreply@example.com" || sent.To != msg.To || sent.Subject != msg.Subject || sent.Text != msg.Text || sent.HTML != msg.HTML {
+		t.Errorf("sent = %+v, want the queued email from the service's address", sent)
+	}
+	if len(updates) != 1 || updates[0]["status"] != models.EmailStatusSent || updates[0]["attempts"] != 1 {
+		t.Errorf("recorded %v, want the email marked sent after one attempt", updates)
+	}
+}
+
+func TestEmailOutboxBackoff(t *testing.T) {
+	worker := NewEmailOutboxWorker(nil, nil, config.EmailConfig{
+		RetryBaseDelay: 10 * time.Second,
+		RetryMaxDelay:  time.Minute,
+	}, zap.NewNop())
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 4, want: time.Minute},
+		{attempts: 10, want: time.Minute},
+	}
+	for _, tt := range tests {
+		got := worker.backoff(tt.attempts)
+		// Up to 20% jitter is added
+		if got < tt.want || got > tt.want+tt.want/5 {
+			t.Errorf("backoff(%d) = %v, want %v plus up to 20%%", tt.attempts, got, tt.want)
+		}
+	}
+}
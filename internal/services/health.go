@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Dependency health states
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// HealthCheckFunc checks a single dependency and returns an error if it is unhealthy
+type HealthCheckFunc func(ctx context.Context) error
+
+// DependencyHealth is the result of checking one dependency
+type DependencyHealth struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport aggregates the results of every dependency check
+type HealthReport struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
+	CheckedAt    time.Time                   `json:"checked_at"`
+}
+
+// Healthy reports whether every dependency is up
+func (r HealthReport) Healthy() bool {
+	return r.Status == HealthStatusUp
+}
+
+// HealthService runs dependency checks in parallel and caches the result briefly
+// so frequent probes don't overload the dependencies
+type HealthService struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	checks   map[string]HealthCheckFunc
+
+	mu     sync.Mutex
+	cached *HealthReport
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(timeout, cacheTTL time.Duration) *HealthService {
+	return &HealthService{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		checks:   map[string]HealthCheckFunc{},
+	}
+}
+
+// Register adds a named dependency check
+func (h *HealthService) Register(name string, check HealthCheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Check returns the cached report or runs every check with the configured timeout
+func (h *HealthService) Check(ctx context.Context) HealthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < h.cacheTTL {
+		return *h.cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := HealthReport{
+		Status:       HealthStatusUp,
+		Dependencies: make(map[string]DependencyHealth, len(h.checks)),
+	}
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func(name string, check HealthCheckFunc) {
+			defer wg.Done()
+			start := time.Now()
+			err := check(ctx)
+			result := DependencyHealth{
+				Status:    HealthStatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = HealthStatusDown
+				result.Error = err.Error()
+			}
+
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			report.Dependencies[name] = result
+			if err != nil {
+				report.Status = HealthStatusDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	report.CheckedAt = time.Now()
+	h.cached = &report
+	return report
+}
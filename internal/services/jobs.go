@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// Job types handled by RegisterJobHandlers, besides JobDeliverWebhook
+const (
+	JobSendEmail          = "email.send"
+	JobPruneEmailOutbox   = "email_outbox.prune"
+	JobCleanupSigningKeys = "signing_keys.cleanup"
+)
+
+// QueueMaintenance holds low priority housekeeping jobs
+const QueueMaintenance = "maintenance"
+
+// SendEmailPayload renders an email template in Locale and queues it for delivery
+type SendEmailPayload struct {
+	To       string          `json:"to"`
+	Template string          `json:"template"`
+	Locale   string          `json:"locale"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// PruneEmailOutboxPayload deletes sent outbox emails older than OlderThan
+type PruneEmailOutboxPayload struct {
+	OlderThan time.Duration `json:"older_than"`
+}
+
+// RegisterJobHandlers registers the handlers of the application's job types
+func RegisterJobHandlers(worker *jobs.Worker, emailService *EmailService, jwtService *JWTService, webhookService *WebhookService, logger *zap.Logger) {
+	worker.Handle(JobSendEmail, func(ctx context.Context, job *jobs.Job) error {
+		var payload SendEmailPayload
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		data, err := email.NewData(payload.Template)
+		if err != nil {
+			return err
+		}
+		if len(payload.Data) > 0 {
+			if err := json.Unmarshal(payload.Data, data); err != nil {
+				return err
+			}
+		}
+		ctx = i18n.WithLocale(ctx, payload.Locale)
+		return emailService.sendTemplate(ctx, payload.To, payload.Template, data)
+	})
+
+	worker.Handle(JobDeliverWebhook, webhookService.HandleDeliveryJob)
+
+	worker.Handle(JobPruneEmailOutbox, func(ctx context.Context, job *jobs.Job) error {
+		payload := PruneEmailOutboxPayload{OlderThan: 30 * 24 * time.Hour}
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		deleted, err := emailService.PruneOutbox(ctx, payload.OlderThan)
+		if err != nil {
+			return err
+		}
+		logger.Info("Pruned email outbox", zap.Int64("deleted", deleted))
+		return nil
+	})
+
+	worker.Handle(JobCleanupSigningKeys, func(ctx context.Context, job *jobs.Job) error {
+		deleted, err := jwtService.DeleteRetiredKeys(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("Deleted retired signing keys", zap.Int64("deleted", deleted))
+		return nil
+	})
+}
@@ -1,24 +1,52 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// keyCacheTTL is how long signing keys are cached before being reloaded from the database
+	keyCacheTTL = time.Minute
+	// keyReloadInterval limits how often an unknown kid can force a reload
+	keyReloadInterval = 5 * time.Second
 )
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	secret string
+	db          *gorm.DB
+	secret      string
+	secretGrace time.Duration
+
+	mu       sync.RWMutex
+	keys     map[string]models.SigningKey
+	current  *models.SigningKey
+	replaced *time.Time
+	loadedAt time.Time
 }
 
-// NewJWTService creates a new JWT service
-func NewJWTService(secret string) *JWTService {
+// NewJWTService creates a new JWT service. Tokens are signed with the newest
+// rotated key in the database, falling back to secret until a key exists.
+// Tokens signed with secret keep validating for secretGrace after the first
+// key replaced it.
+func NewJWTService(db *gorm.DB, secret string, secretGrace time.Duration) *JWTService {
 	return &JWTService{
-		secret: secret,
+		db:          db,
+		secret:      secret,
+		secretGrace: secretGrace,
+		keys:        map[string]models.SigningKey{},
 	}
 }
 
@@ -31,7 +59,7 @@ type Claims struct {
 }
 
 // GenerateToken generates a JWT token for a user
-func (j *JWTService) GenerateToken(user *models.User, expiresIn time.Duration) (string, error) {
+func (j *JWTService) GenerateToken(ctx context.Context, user *models.User, expiresIn time.Duration) (string, error) {
 	// Only ID, Email, and Username are used for claims
 	claims := &Claims{
 		UserID:   user.ID,
@@ -44,16 +72,31 @@ func (j *JWTService) GenerateToken(user *models.User, expiresIn time.Duration) (
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if key := j.signingKey(ctx); key != nil {
+		token.Header["kid"] = key.ID
+		return token.SignedString([]byte(key.Secret))
+	}
 	return token.SignedString([]byte(j.secret))
 }
 
 // ValidateToken validates a JWT token and returns claims
-func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+func (j *JWTService) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(j.secret), nil
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			if !j.secretValid(ctx) {
+				return nil, fmt.Errorf("tokens without a kid are no longer accepted")
+			}
+			return []byte(j.secret), nil
+		}
+		key, ok := j.verificationKey(ctx, kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		return []byte(key.Secret), nil
 	})
 
 	if err != nil {
@@ -66,3 +109,135 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// RotateKey creates a new signing key and schedules the previous keys to stop
+// validating after grace, so tokens they signed keep working until then
+func (j *JWTService) RotateKey(ctx context.Context, grace time.Duration) (*models.SigningKey, error) {
+	kid := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := models.SigningKey{
+		ID:     hex.EncodeToString(kid),
+		Secret: base64.StdEncoding.EncodeToString(secret),
+	}
+
+	now := time.Now()
+	err := j.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("retires_at IS NOT NULL AND retires_at < ?", now).Delete(&models.SigningKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SigningKey{}).Where("retires_at IS NULL").Update("retires_at", now.Add(grace)).Error; err != nil {
+			return err
+		}
+		// Only the first rotation replaces the static secret; later ones
+		// leave the recorded time alone
+		settings := models.JWTSettings{ID: true, SecretReplacedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&settings).Error; err != nil {
+			return err
+		}
+		return tx.Create(&key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.reloadKeys(ctx); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// DeleteRetiredKeys deletes keys past their retirement and returns how many
+func (j *JWTService) DeleteRetiredKeys(ctx context.Context) (int64, error) {
+	result := j.db.WithContext(ctx).Where("retires_at IS NOT NULL AND retires_at < ?", time.Now()).Delete(&models.SigningKey{})
+	return result.RowsAffected, result.Error
+}
+
+// signingKey returns the active rotated key, or nil to sign with the static secret
+func (j *JWTService) signingKey(ctx context.Context) *models.SigningKey {
+	j.mu.RLock()
+	stale := time.Since(j.loadedAt) > keyCacheTTL
+	j.mu.RUnlock()
+	if stale {
+		// Keep serving the cached keys if the database is unavailable
+		_ = j.reloadKeys(ctx)
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.current
+}
+
+// secretValid reports whether tokens signed with the static secret still
+// validate: until the first key replaced it, and for the grace after that
+func (j *JWTService) secretValid(ctx context.Context) bool {
+	j.mu.RLock()
+	stale := time.Since(j.loadedAt) > keyCacheTTL
+	j.mu.RUnlock()
+	if stale {
+		_ = j.reloadKeys(ctx)
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.replaced == nil || time.Now().Before(j.replaced.Add(j.secretGrace))
+}
+
+// verificationKey looks up a key by kid, reloading once if it isn't cached yet
+func (j *JWTService) verificationKey(ctx context.Context, kid string) (models.SigningKey, bool) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	canReload := time.Since(j.loadedAt) > keyReloadInterval
+	j.mu.RUnlock()
+	if !ok && canReload {
+		if err := j.reloadKeys(ctx); err != nil {
+			return models.SigningKey{}, false
+		}
+		j.mu.RLock()
+		key, ok = j.keys[kid]
+		j.mu.RUnlock()
+	}
+	if ok && key.RetiresAt != nil && key.RetiresAt.Before(time.Now()) {
+		return models.SigningKey{}, false
+	}
+	return key, ok
+}
+
+// reloadKeys refreshes the cached keys from the database
+func (j *JWTService) reloadKeys(ctx context.Context) error {
+	var keys []models.SigningKey
+	err := j.db.WithContext(ctx).Where("retires_at IS NULL OR retires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&keys).Error
+	// Recorded once, so deleting old keys doesn't move the secret's grace
+	var settings []models.JWTSettings
+	if err == nil {
+		err = j.db.WithContext(ctx).Limit(1).Find(&settings).Error
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.loadedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	j.keys = make(map[string]models.SigningKey, len(keys))
+	j.current = nil
+	j.replaced = nil
+	if len(settings) > 0 {
+		j.replaced = &settings[0].SecretReplacedAt
+	}
+	for i, key := range keys {
+		j.keys[key.ID] = key
+		if j.current == nil && key.RetiresAt == nil {
+			j.current = &keys[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/geoip"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxUserAgentLength bounds the user agents stored with known devices
+	maxUserAgentLength = 512
+	// minTravelDistanceKm ignores jumps within the error of city-level
+	// geolocation when looking for impossible travel
+	minTravelDistanceKm = 500
+)
+
+// LoginAlertService remembers the devices users sign in from and alerts them
+// about sign-ins from new devices or from implausibly far away
+type LoginAlertService struct {
+	db                  *gorm.DB
+	redisService        *RedisService
+	emailService        *EmailService
+	notificationService *NotificationService
+	bus                 *events.Bus
+	locator             *geoip.Locator
+	cfg                 config.LoginAlertsConfig
+}
+
+// NewLoginAlertService creates a new login alert service. Sign-ins are only
+// located, and checked for impossible travel, when locator is not nil.
+// Revoked sessions are published on bus.
+func NewLoginAlertService(db *gorm.DB, redisService *RedisService, emailService *EmailService, notificationService *NotificationService, bus *events.Bus, locator *geoip.Locator, cfg config.LoginAlertsConfig) *LoginAlertService {
+	return &LoginAlertService{
+		db:                  db,
+		redisService:        redisService,
+		emailService:        emailService,
+		notificationService: notificationService,
+		bus:                 bus,
+		locator:             locator,
+		cfg:                 cfg,
+	}
+}
+
+// LoginCheck is what Check found out about a sign-in
+type LoginCheck struct {
+	// Device is the device signing in, as Record will store it
+	Device models.KnownDevice
+	// NewDevice is set when the user agent or IP address wasn't seen before.
+	// A user's first sign-in is not new: there is nothing to compare it to.
+	NewDevice bool
+	// ImpossibleTravel is set when the sign-in is too far from the previous
+	// one to have travelled there since
+	ImpossibleTravel bool
+}
+
+// StepUp reports whether flagged password sign-ins need a one-time code
+func (l *LoginAlertService) StepUp() bool {
+	return l.cfg.StepUp
+}
+
+// Check compares a sign-in with the user's known devices without recording it
+func (l *LoginAlertService) Check(ctx context.Context, user *models.User, client models.ClientInfo) (*LoginCheck, error) {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	check := &LoginCheck{
+		Device: models.KnownDevice{UserID: user.ID, UserAgent: userAgent, IP: client.IP},
+	}
+	if loc, ok := l.locator.Lookup(client.IP, user.Locale); ok {
+		check.Device.Location = loc.String()
+		if loc.HasCoordinates {
+			check.Device.Latitude = &loc.Latitude
+			check.Device.Longitude = &loc.Longitude
+		}
+	}
+
+	var devices []models.KnownDevice
+	if err := l.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return check, nil
+	}
+
+	knownAgent, knownIP := false, false
+	for _, device := range devices {
+		knownAgent = knownAgent || device.UserAgent == userAgent
+		knownIP = knownIP || device.IP == client.IP
+	}
+	check.NewDevice = !knownAgent || !knownIP
+	check.ImpossibleTravel = l.impossibleTravel(devices[0], check.Device, time.Now())
+	return check, nil
+}
+
+// impossibleTravel reports whether getting from the previous sign-in to the
+// current one by now would take more than the configured speed
+func (l *LoginAlertService) impossibleTravel(previous, current models.KnownDevice, now time.Time) bool {
+	if previous.Latitude == nil || current.Latitude == nil {
+		return false
+	}
+	distance := geoip.DistanceKm(*previous.Latitude, *previous.Longitude, *current.Latitude, *current.Longitude)
+	if distance < minTravelDistanceKm {
+		return false
+	}
+	hours := now.Sub(previous.LastSeenAt).Hours()
+	return hours <= 0 || distance/hours > l.cfg.MaxTravelSpeed
+}
+
+// Record remembers the device of a completed sign-in, forgetting the least
+// recently used devices beyond the limit, and alerts the user if Check
+// flagged the sign-in
+func (l *LoginAlertService) Record(ctx context.Context, user *models.User, check *LoginCheck) error {
+	now := time.Now()
+	device := check.Device
+	device.FirstSeenAt = now
+	device.LastSeenAt = now
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A known device keeps its ID and first sighting
+		err := tx.Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "user_agent"}, {Name: "ip"}},
+				DoUpdates: clause.AssignmentColumns([]string{"location", "latitude", "longitude", "last_seen_at"}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}}},
+		).Create(&device).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DELETE FROM known_devices
+			WHERE user_id = ? AND id NOT IN (
+				SELECT id FROM known_devices WHERE user_id = ? ORDER BY last_seen_at DESC LIMIT ?
+			)`,
+			user.ID, user.ID, l.cfg.MaxDevices,
+		).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if !check.NewDevice && !check.ImpossibleTravel {
+		return nil
+	}
+	return l.alert(ctx, user, device, check.ImpossibleTravel)
+}
+
+// alert emails the user about a sign-in from device, whatever their
+// notification preferences, and adds it to their inbox if they keep one
+func (l *LoginAlertService) alert(ctx context.Context, user *models.User, device models.KnownDevice, suspicious bool) error {
+	revokeURL, err := l.revokeURL(ctx, user.ID, device.ID)
+	if err != nil {
+		return err
+	}
+	notificationType := models.NotificationTypeNewLogin
+	if suspicious {
+		notificationType = models.NotificationTypeSuspiciousLogin
+	}
+	name := user.FirstName
+	if name == "" {
+		name = user.Username
+	}
+	emailCtx := ctx
+	if user.Locale != "" {
+		emailCtx = i18n.WithLocale(ctx, user.Locale)
+	}
+	err = l.emailService.SendNewLoginAlert(emailCtx, user.Email, email.NewLoginData{
+		Name:       name,
+		Time:       device.LastSeenAt,
+		IP:         device.IP,
+		UserAgent:  device.UserAgent,
+		Location:   device.Location,
+		RevokeURL:  revokeURL,
+		Suspicious: suspicious,
+	})
+	if err != nil {
+		return err
+	}
+	return l.notificationService.Notify(ctx, user.ID, Notice{
+		Category: models.NotificationCategorySecurity,
+		Type:     notificationType,
+		Payload: map[string]string{
+			"device_id":  device.ID.String(),
+			"ip":         device.IP,
+			"user_agent": device.UserAgent,
+			"location":   device.Location,
+		},
+	})
+}
+
+// revokeURL returns a single-use "this wasn't me" link, or "" if no page is
+// configured to receive it
+func (l *LoginAlertService) revokeURL(ctx context.Context, userID, deviceID uuid.UUID) (string, error) {
+	if l.cfg.RevokeURL == "" {
+		return "", nil
+	}
+	link, err := url.Parse(l.cfg.RevokeURL)
+	if err != nil {
+		return "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := l.redisService.Set(ctx, revokeTokenKey(token), userID.String()+"|"+deviceID.String(), l.cfg.RevokeTTL); err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// RevokeSessions consumes a "this wasn't me" token: every token issued to the
+// user so far stops working, their open streams are closed and the device
+// that signed in is forgotten
+func (l *LoginAlertService) RevokeSessions(ctx context.Context, token string) error {
+	value, err := l.redisService.Client().GetDel(ctx, revokeTokenKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return apperrors.New(apperrors.CodeAuthInvalidToken, "Invalid or expired link")
+	}
+	if err != nil {
+		return err
+	}
+	userPart, devicePart, _ := strings.Cut(value, "|")
+	userID, err := uuid.Parse(userPart)
+	if err != nil {
+		return err
+	}
+	deviceID, err := uuid.Parse(devicePart)
+	if err != nil {
+		return err
+	}
+
+	return transaction(ctx, l.db, l.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("sessions_revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.KnownDevice{}).Error; err != nil {
+			return err
+		}
+		return emit(events.SessionsRevoked{UserID: userID})
+	})
+}
+
+// revokeTokenKey keeps plain tokens out of Redis
+func revokeTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "login_alert:revoke:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/realtime"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationService keeps users' inboxes and delivers notifications
+// through the channels each user chose for its category
+type NotificationService struct {
+	db           *gorm.DB
+	emailService *EmailService
+	hub          *realtime.Hub
+}
+
+// NewNotificationService creates a new notification service. Notifications
+// are pushed to open streams through hub unless it is nil.
+func NewNotificationService(db *gorm.DB, emailService *EmailService, hub *realtime.Hub) *NotificationService {
+	return &NotificationService{
+		db:           db,
+		emailService: emailService,
+		hub:          hub,
+	}
+}
+
+// Notice is a notification to deliver to a user
+type Notice struct {
+	Category string
+	Type     string
+	Payload  interface{}
+	// EventID is the bus event the notice is raised for. The notice is only
+	// delivered once per event, however often the event is redelivered.
+	EventID uuid.UUID
+	// EmailTemplate and EmailData are sent to users who want emails for the
+	// category; notices without a template are never emailed
+	EmailTemplate string
+	EmailData     interface{}
+}
+
+// ListNotificationsOptions filters and pages a user's inbox
+type ListNotificationsOptions struct {
+	Limit    int
+	Cursor   string
+	Unread   bool
+	Category string
+}
+
+// Subscribe turns bus events into notifications
+func (n *NotificationService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.NameUserRegistered, func(ctx context.Context, event events.Event) error {
+		user := event.(events.UserRegistered).User
+		return n.Notify(ctx, user.ID, Notice{
+			Category:      models.NotificationCategoryAccount,
+			Type:          models.NotificationTypeWelcome,
+			Payload:       map[string]string{"username": user.Username},
+			EventID:       events.IDFromContext(ctx),
+			EmailTemplate: email.TemplateWelcome,
+			EmailData:     email.WelcomeData{Name: displayName(user)},
+		})
+	})
+	bus.Subscribe(events.NamePasswordChanged, func(ctx context.Context, event events.Event) error {
+		user := event.(events.PasswordChanged).User
+		return n.Notify(ctx, user.ID, Notice{
+			Category:      models.NotificationCategorySecurity,
+			Type:          models.NotificationTypePasswordChanged,
+			Payload:       map[string]string{},
+			EventID:       events.IDFromContext(ctx),
+			EmailTemplate: email.TemplatePasswordChanged,
+			EmailData:     email.PasswordChangedData{Name: displayName(user), Time: time.Now()},
+		})
+	})
+}
+
+// Notify delivers notice to the user's inbox, email and open streams, as
+// their preferences for its category allow. The inbox entry and the email
+// are written in one transaction, so neither is kept without the other; a
+// failing push doesn't undo them. Only notifications kept in the inbox are
+// pushed, since the pushed event refers to the inbox entry. A notice whose
+// event already added an inbox entry is dropped.
+func (n *NotificationService) Notify(ctx context.Context, userID uuid.UUID, notice Notice) error {
+	pref, err := n.preference(ctx, userID, notice.Category)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(notice.Payload)
+	if err != nil {
+		return err
+	}
+	notification := models.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Category:  notice.Category,
+		Type:      notice.Type,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+	if notice.EventID != uuid.Nil {
+		notification.EventID = &notice.EventID
+	}
+
+	duplicate := false
+	err = n.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pref.Inbox {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+			if result.Error != nil {
+				return result.Error
+			}
+			if duplicate = result.RowsAffected == 0; duplicate {
+				return nil
+			}
+		}
+		if pref.Email && notice.EmailTemplate != "" && n.emailService != nil {
+			return n.email(ctx, tx, userID, notice)
+		}
+		return nil
+	})
+	if err != nil || duplicate {
+		return err
+	}
+	if pref.Inbox && pref.Push && n.hub != nil {
+		return n.hub.Publish(ctx, userID, events.NotificationCreated{Notification: notification})
+	}
+	return nil
+}
+
+// displayName is how emails greet the user
+func displayName(user models.UserResponse) string {
+	if user.FirstName != "" {
+		return user.FirstName
+	}
+	return user.Username
+}
+
+// email queues notice's template in tx for the user, in their language
+func (n *NotificationService) email(ctx context.Context, tx *gorm.DB, userID uuid.UUID, notice Notice) error {
+	var user models.User
+	if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+		return notFound(err)
+	}
+	if user.Locale != "" {
+		ctx = i18n.WithLocale(ctx, user.Locale)
+	}
+	return n.emailService.WithTx(tx).sendTemplate(ctx, user.Email, notice.EmailTemplate, notice.EmailData)
+}
+
+// List returns a page of the user's notifications, newest first
+func (n *NotificationService) List(ctx context.Context, userID uuid.UUID, opts ListNotificationsOptions) (*models.NotificationPage, error) {
+	query := n.db.WithContext(ctx).Where("user_id = ?", userID)
+	if opts.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+	if opts.Category != "" {
+		query = query.Where("category = ?", opts.Category)
+	}
+	if opts.Cursor != "" {
+		createdAt, id, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidInput, "Invalid cursor", err)
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+	}
+
+	page := &models.NotificationPage{}
+	// Fetch one more than asked to know whether another page follows
+	if err := query.Order("created_at DESC, id DESC").Limit(opts.Limit + 1).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(page.Items) > opts.Limit {
+		page.Items = page.Items[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	err := n.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&page.UnreadCount).Error
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// MarkRead marks one of the user's notifications read and returns it
+func (n *NotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) (*models.Notification, error) {
+	err := n.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	var notification models.Notification
+	if err := n.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return nil, notificationNotFound(err)
+	}
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user read and returns how many
+func (n *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := n.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// Delete deletes one of the user's notifications
+func (n *NotificationService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result := n.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.New(apperrors.CodeNotFound, "Notification not found")
+	}
+	return nil
+}
+
+// Preferences returns the user's preferences for every category, with
+// defaults for the categories they haven't set
+func (n *NotificationService) Preferences(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	var saved []models.NotificationPreference
+	if err := n.db.WithContext(ctx).Where("user_id = ?", userID).Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	prefs := make([]models.NotificationPreference, 0, len(models.NotificationCategories))
+	for _, category := range models.NotificationCategories {
+		pref := models.DefaultNotificationPreference(userID, category)
+		if i := slices.IndexFunc(saved, func(p models.NotificationPreference) bool { return p.Category == category }); i >= 0 {
+			pref = saved[i]
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences changes the channels given in input and returns every preference
+func (n *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, input models.NotificationPreferencesInput) ([]models.NotificationPreference, error) {
+	err := n.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, change := range input.Preferences {
+			pref := models.DefaultNotificationPreference(userID, change.Category)
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND category = ?", userID, change.Category).
+				Take(&pref).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if change.Inbox != nil {
+				pref.Inbox = *change.Inbox
+			}
+			if change.Email != nil {
+				pref.Email = *change.Email
+			}
+			if change.Push != nil {
+				pref.Push = *change.Push
+			}
+			if err := tx.Save(&pref).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n.Preferences(ctx, userID)
+}
+
+// preference returns the user's preference for category
+func (n *NotificationService) preference(ctx context.Context, userID uuid.UUID, category string) (models.NotificationPreference, error) {
+	pref := models.DefaultNotificationPreference(userID, category)
+	err := n.db.WithContext(ctx).Where("user_id = ? AND category = ?", userID, category).Take(&pref).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return pref, err
+	}
+	return pref, nil
+}
+
+// encodeCursor returns an opaque cursor pointing after the notification
+// created at createdAt with id
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+// decodeCursor reverses encodeCursor
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	timestamp, idPart, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, errors.New("missing separator")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return createdAt, id, nil
+}
+
+// notificationNotFound maps a missing row to a NOT_FOUND error
+func notificationNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "Notification not found", err)
+	}
+	return err
+}
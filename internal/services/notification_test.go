@@ -0,0 +1,55 @@
+package services
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNotificationCursor(t *testing.T) {
+	id := uuid.MustParse("0b6f1c9e-7d3a-4f57-9a43-2f1de1b8c001")
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{name: "utc", createdAt: time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{name: "nanoseconds", createdAt: time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC)},
+		{name: "other zone", createdAt: time.Date(2026, 3, 1, 14, 30, 0, 500, time.FixedZone("CEST", 2*60*60))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := encodeCursor(tt.createdAt, id)
+			createdAt, gotID, err := decodeCursor(cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !createdAt.Equal(tt.createdAt) || gotID != id {
+				t.Errorf("decodeCursor(encodeCursor(%s, %s)) = %s, %s", tt.createdAt, id, createdAt, gotID)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("2026-03-01T12:30:00Z|x"))},
+		{name: "no separator", cursor: encode("2026-03-01T12:30:00Z")},
+		{name: "bad time", cursor: encode("yesterday|0b6f1c9e-7d3a-4f57-9a43-2f1de1b8c001")},
+		{name: "bad id", cursor: encode("2026-03-01T12:30:00Z|42")},
+		{name: "empty", cursor: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decodeCursor(%q) succeeded", tt.cursor)
+			}
+		})
+	}
+}
@@ -1,32 +1,215 @@
 package services
 
 import (
-	"math/rand"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
 	"strconv"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/redis/go-redis/v9"
 )
 
+// OtpService issues and checks one-time codes, delivered through the enabled
+// channels. Codes are stored hashed in Redis.
 type OtpService struct {
-	emailService *EmailService
+	redisService *RedisService
+	cfg          config.OTPConfig
+	channels     map[string]OTPChannel
 }
 
-func NewOtpService(emailService *EmailService) *OtpService {
+// NewOtpService creates an OTP service. cfg.Channels picks which of channels
+// are enabled and which is the default.
+func NewOtpService(redisService *RedisService, cfg config.OTPConfig, channels ...OTPChannel) *OtpService {
+	byName := make(map[string]OTPChannel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
 	return &OtpService{
-		emailService: emailService,
+		redisService: redisService,
+		cfg:          cfg,
+		channels:     byName,
 	}
 }
 
+// GenerateOtp returns a random 6-digit code
 func GenerateOtp() string {
-	rand.Seed(time.Now().UnixNano())
-	otp := rand.Intn(1000000) // Generate a random 6-digit number
-	return strconv.Itoa(otp)
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
+
+// Channel returns the enabled channel called name, or the default channel
+// when name is empty
+func (o *OtpService) Channel(name string) (OTPChannel, error) {
+	if name == "" && len(o.cfg.Channels) > 0 {
+		name = o.cfg.Channels[0]
+	}
+	for _, enabled := range o.cfg.Channels {
+		if enabled == name {
+			if channel, ok := o.channels[name]; ok {
+				return channel, nil
+			}
+		}
+	}
+	return nil, apperrors.New(apperrors.CodeInvalidInput, "OTP channel is not available: "+name)
 }
 
-func (o *OtpService) SendOtp(email, subject string) error {
-	otp := GenerateOtp()
-	if subject == "" {
-		subject = "Your OTP Code"
+// TTL is how long a code stays valid
+func (o *OtpService) TTL() time.Duration {
+	return o.cfg.TTL
+}
+
+// Send issues a new sign-in code for user and delivers it through channel,
+// at the request of client. It fails with TOO_MANY_REQUESTS once a quota is
+// used up.
+func (o *OtpService) Send(ctx context.Context, user *models.User, channel OTPChannel, client models.ClientInfo) error {
+	destination := channel.Destination(user)
+	if destination == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "The account has no destination for OTP channel "+channel.Name())
+	}
+	return o.issue(ctx, channel, destination, client, otpCodeKey(user.ID), otpAttemptsKey(user.ID))
+}
+
+// SendPhoneVerification texts a code to user's phone number, which confirms
+// it with VerifyPhone. It counts against the same quotas as Send.
+func (o *OtpService) SendPhoneVerification(ctx context.Context, user *models.User, client models.ClientInfo) error {
+	channel, err := o.Channel(OTPChannelSMS)
+	if err != nil {
+		return err
+	}
+	if user.Phone == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "The account has no phone number")
 	}
-	body := "Your OTP code is: " + otp
-	return o.emailService.SendEmail(email, subject, body)
+	return o.issue(ctx, channel, user.Phone, client, phoneCodeKey(user), phoneAttemptsKey(user))
+}
+
+// issue stores a new code under codeKey and delivers it to destination
+func (o *OtpService) issue(ctx context.Context, channel OTPChannel, destination string, client models.ClientInfo, codeKey, attemptsKey string) error {
+	if err := o.checkQuota(ctx, channel.Name(), destination, client.IP); err != nil {
+		return err
+	}
+
+	code := GenerateOtp()
+	if err := o.redisService.Set(ctx, codeKey, hashOtp(code), o.cfg.TTL); err != nil {
+		return err
+	}
+	if err := o.redisService.Delete(ctx, attemptsKey); err != nil {
+		return err
+	}
+	if err := channel.Send(ctx, destination, code, o.cfg.TTL); err != nil {
+		return err
+	}
+	metrics.OTPSentTotal.WithLabelValues(channel.Name()).Inc()
+	return nil
+}
+
+// Verify consumes user's current sign-in code if code matches it. Too many
+// wrong guesses invalidate the code.
+func (o *OtpService) Verify(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	return o.verify(ctx, otpCodeKey(userID), otpAttemptsKey(userID), code)
+}
+
+// VerifyPhone consumes the code sent by SendPhoneVerification to user's
+// current phone number if code matches it
+func (o *OtpService) VerifyPhone(ctx context.Context, user *models.User, code string) (bool, error) {
+	return o.verify(ctx, phoneCodeKey(user), phoneAttemptsKey(user), code)
+}
+
+func (o *OtpService) verify(ctx context.Context, codeKey, attemptsKey, code string) (bool, error) {
+	stored, err := o.redisService.Get(ctx, codeKey)
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashOtp(code))) == 1 {
+		return true, o.redisService.Delete(ctx, codeKey, attemptsKey)
+	}
+
+	attempts, err := o.redisService.Increment(ctx, attemptsKey, o.cfg.TTL)
+	if err != nil {
+		return false, err
+	}
+	if attempts >= int64(o.cfg.MaxAttempts) {
+		return false, o.redisService.Delete(ctx, codeKey, attemptsKey)
+	}
+	return false, nil
+}
+
+// otpQuota caps the codes counted under key in each window
+type otpQuota struct {
+	key     string
+	name    string
+	window  time.Duration
+	limit   int
+	message string
+}
+
+// checkQuota counts a send to destination against its hourly and daily
+// quotas. Texts also count against the client IP's hourly quota and the
+// global daily quota, so numbers registered in bulk can't pump SMS.
+func (o *OtpService) checkQuota(ctx context.Context, channel, destination, ip string) error {
+	const destinationMessage = "Too many codes sent to this destination, try again later"
+	quotas := []otpQuota{
+		{"otp:quota:hour:" + channel + ":" + destination, "hour", time.Hour, o.cfg.HourlyLimit, destinationMessage},
+		{"otp:quota:day:" + channel + ":" + destination, "day", 24 * time.Hour, o.cfg.DailyLimit, destinationMessage},
+	}
+	if channel == OTPChannelSMS {
+		if ip != "" {
+			quotas = append(quotas, otpQuota{"otp:quota:hour:sms-ip:" + ip, "hour", time.Hour, o.cfg.SMSHourlyLimitPerIP, "Too many codes sent from this address, try again later"})
+		}
+		quotas = append(quotas, otpQuota{"otp:quota:day:sms-total", "day", 24 * time.Hour, o.cfg.SMSDailyLimit, "Too many codes sent, try again later"})
+	}
+	for _, q := range quotas {
+		if q.limit <= 0 {
+			continue
+		}
+		count, err := o.redisService.Increment(ctx, q.key, q.window)
+		if err != nil {
+			return err
+		}
+		if count > int64(q.limit) {
+			metrics.OTPQuotaExceededTotal.WithLabelValues(channel).Inc()
+			return apperrors.New(apperrors.CodeTooManyRequests, q.message).
+				WithDetails(map[string]string{"retry_window": q.name, "limit": strconv.Itoa(q.limit)})
+		}
+	}
+	return nil
+}
+
+func otpCodeKey(userID uuid.UUID) string {
+	return "otp:code:" + userID.String()
+}
+
+func otpAttemptsKey(userID uuid.UUID) string {
+	return "otp:attempts:" + userID.String()
+}
+
+// phoneCodeKey holds the code confirming user's current phone number
+func phoneCodeKey(user *models.User) string {
+	return "otp:phone:" + user.ID.String() + ":" + user.Phone
+}
+
+func phoneAttemptsKey(user *models.User) string {
+	return "otp:phone_attempts:" + user.ID.String() + ":" + user.Phone
+}
+
+// hashOtp keeps plain codes out of Redis
+func hashOtp(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
 }
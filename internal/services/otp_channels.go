@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/email"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/sms"
+	"go.uber.org/zap"
+)
+
+// OTP channel names accepted by OTP_CHANNELS and the OTP request endpoint
+const (
+	OTPChannelEmail   = "email"
+	OTPChannelSMS     = "sms"
+	OTPChannelConsole = "console"
+)
+
+// OTPChannel delivers one-time codes
+type OTPChannel interface {
+	// Name identifies the channel in requests, quotas and metrics
+	Name() string
+	// Destination returns where user receives codes, or "" if they can't
+	Destination(user *models.User) string
+	// Send delivers code, valid for ttl, to destination
+	Send(ctx context.Context, destination, code string, ttl time.Duration) error
+}
+
+// EmailOTPChannel sends codes with the OTP email template
+type EmailOTPChannel struct {
+	emailService *EmailService
+}
+
+// NewEmailOTPChannel creates an email channel
+func NewEmailOTPChannel(emailService *EmailService) *EmailOTPChannel {
+	return &EmailOTPChannel{emailService: emailService}
+}
+
+func (c *EmailOTPChannel) Name() string { return OTPChannelEmail }
+
+func (c *EmailOTPChannel) Destination(user *models.User) string { return user.Email }
+
+func (c *EmailOTPChannel) Send(ctx context.Context, destination, code string, ttl time.Duration) error {
+	return c.emailService.SendOTP(ctx, destination, email.OTPData{Code: code, ExpiresIn: ttl})
+}
+
+// SMSOTPChannel texts codes to the user's phone number, once it is verified
+type SMSOTPChannel struct {
+	sender  sms.Sender
+	appName string
+}
+
+// NewSMSOTPChannel creates an SMS channel
+func NewSMSOTPChannel(sender sms.Sender, appName string) *SMSOTPChannel {
+	return &SMSOTPChannel{sender: sender, appName: appName}
+}
+
+func (c *SMSOTPChannel) Name() string { return OTPChannelSMS }
+
+func (c *SMSOTPChannel) Destination(user *models.User) string {
+	// Phone numbers are self-declared until verified
+	if user.PhoneVerifiedAt == nil {
+		return ""
+	}
+	return user.Phone
+}
+
+func (c *SMSOTPChannel) Send(ctx context.Context, destination, code string, ttl time.Duration) error {
+	body := i18n.T(ctx, "sms.otp", i18n.Params{
+		"app":     c.appName,
+		"code":    code,
+		"minutes": strconv.Itoa(int(ttl.Round(time.Minute) / time.Minute)),
+	})
+	return c.sender.Send(ctx, destination, body)
+}
+
+// ConsoleOTPChannel writes codes to the log, for development only
+type ConsoleOTPChannel struct {
+	logger *zap.Logger
+}
+
+// NewConsoleOTPChannel creates a console channel
+func NewConsoleOTPChannel(logger *zap.Logger) *ConsoleOTPChannel {
+	return &ConsoleOTPChannel{logger: logger}
+}
+
+func (c *ConsoleOTPChannel) Name() string { return OTPChannelConsole }
+
+func (c *ConsoleOTPChannel) Destination(user *models.User) string { return user.Email }
+
+func (c *ConsoleOTPChannel) Send(ctx context.Context, destination, code string, ttl time.Duration) error {
+	c.logger.Info("OTP code", zap.String("destination", destination), zap.String("code", code), zap.Duration("expires_in", ttl))
+	return nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/sms"
+	"go.uber.org/zap"
+)
+
+// newTestRedisService returns a Redis service backed by an in-memory server
+func newTestRedisService(t *testing.T) *RedisService {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, _ := strconv.Atoi(mr.Port())
+	redisService, err := NewRedisService(context.Background(), config.RedisConfig{Host: mr.Host(), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { redisService.Close() })
+	return redisService
+}
+
+// newTestOtpService returns an OTP service with the email, sms and console
+// channels registered, texting through the returned fake
+func newTestOtpService(t *testing.T, cfg config.OTPConfig) (*OtpService, *sms.Fake) {
+	t.Helper()
+	if cfg.TTL == 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 3
+	}
+	emailService, _ := newMemoryEmailService(t, dryRunDB(t, func(interface{}) {}, func(interface{}) {}))
+	sender := sms.NewFake(zap.NewNop())
+	otpService := NewOtpService(newTestRedisService(t), cfg,
+		NewEmailOTPChannel(emailService),
+		NewSMSOTPChannel(sender, "Acme"),
+		NewConsoleOTPChannel(zap.NewNop()),
+	)
+	return otpService, sender
+}
+
+// hasCode reports whether err is an application error with code
+func hasCode(err error, code apperrors.Code) bool {
+	appErr, ok := apperrors.As(err)
+	return ok && appErr.Code == code
+}
+
+// verifiedPhoneUser returns a user whose phone number can receive codes
+func verifiedPhoneUser(phone string) *models.User {
+	now := time.Now()
+	return &models.User{ID: uuid.New(), Email: phone + "@example.com", Phone: phone, PhoneVerifiedAt: &now}
+}
+
+func TestOtpChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  []string
+		channel  string
+		want     string
+		wantFail bool
+	}{
+		{name: "default", enabled: []string{"sms", "email"}, want: "sms"},
+		{name: "named", enabled: []string{"email", "sms"}, channel: "sms", want: "sms"},
+		{name: "not enabled", enabled: []string{"email"}, channel: "sms", wantFail: true},
+		{name: "unknown", enabled: []string{"email", "carrier-pigeon"}, channel: "carrier-pigeon", wantFail: true},
+		{name: "none enabled", wantFail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			otpService, _ := newTestOtpService(t, config.OTPConfig{Channels: tt.enabled})
+			channel, err := otpService.Channel(tt.channel)
+			if tt.wantFail {
+				if !hasCode(err, apperrors.CodeInvalidInput) {
+					t.Errorf("Channel(%q) error = %v, want INVALID_INPUT", tt.channel, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if channel.Name() != tt.want {
+				t.Errorf("Channel(%q) = %s, want %s", tt.channel, channel.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSMSDestination(t *testing.T) {
+	channel := NewSMSOTPChannel(sms.NewFake(zap.NewNop()), "Acme")
+	verified := verifiedPhoneUser("+14155550100")
+	unverified := &models.User{ID: uuid.New(), Phone: "+14155550101"}
+
+	if got := channel.Destination(verified); got != verified.Phone {
+		t.Errorf("verified phone destination = %q, want %q", got, verified.Phone)
+	}
+	if got := channel.Destination(unverified); got != "" {
+		t.Errorf("unverified phone destination = %q, want none", got)
+	}
+}
+
+func TestOtpSendQuota(t *testing.T) {
+	type send struct {
+		phone string
+		ip    string
+	}
+	tests := []struct {
+		name    string
+		cfg     config.OTPConfig
+		channel string
+		sends   []send
+		// failAt is the index of the first send over quota, -1 if none is
+		failAt int
+	}{
+		{
+			name:    "destination hourly",
+			cfg:     config.OTPConfig{HourlyLimit: 2, DailyLimit: 10},
+			channel: OTPChannelSMS,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550100", "10.0.0.2"}, {"+14155550100", "10.0.0.3"}},
+			failAt:  2,
+		},
+		{
+			name:    "destination daily",
+			cfg:     config.OTPConfig{HourlyLimit: 10, DailyLimit: 1},
+			channel: OTPChannelSMS,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550100", "10.0.0.2"}},
+			failAt:  1,
+		},
+		{
+			name:    "sms per IP",
+			cfg:     config.OTPConfig{HourlyLimit: 10, DailyLimit: 10, SMSHourlyLimitPerIP: 2},
+			channel: OTPChannelSMS,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550101", "10.0.0.1"}, {"+14155550102", "10.0.0.1"}},
+			failAt:  2,
+		},
+		{
+			name:    "sms in total",
+			cfg:     config.OTPConfig{HourlyLimit: 10, DailyLimit: 10, SMSDailyLimit: 2},
+			channel: OTPChannelSMS,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550101", "10.0.0.2"}, {"+14155550102", "10.0.0.3"}},
+			failAt:  2,
+		},
+		{
+			name:    "email ignores sms caps",
+			cfg:     config.OTPConfig{HourlyLimit: 10, DailyLimit: 10, SMSHourlyLimitPerIP: 1, SMSDailyLimit: 1},
+			channel: OTPChannelEmail,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550101", "10.0.0.1"}},
+			failAt:  -1,
+		},
+		{
+			name:    "no limits",
+			cfg:     config.OTPConfig{},
+			channel: OTPChannelSMS,
+			sends:   []send{{"+14155550100", "10.0.0.1"}, {"+14155550100", "10.0.0.1"}, {"+14155550100", "10.0.0.1"}},
+			failAt:  -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Channels = []string{tt.channel}
+			otpService, _ := newTestOtpService(t, tt.cfg)
+			channel, err := otpService.Channel(tt.channel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			users := map[string]*models.User{}
+			for i, send := range tt.sends {
+				user, ok := users[send.phone]
+				if !ok {
+					user = verifiedPhoneUser(send.phone)
+					users[send.phone] = user
+				}
+				err := otpService.Send(context.Background(), user, channel, models.ClientInfo{IP: send.ip})
+				switch {
+				case i == tt.failAt:
+					if !hasCode(err, apperrors.CodeTooManyRequests) {
+						t.Fatalf("send %d error = %v, want TOO_MANY_REQUESTS", i, err)
+					}
+					return
+				case err != nil:
+					t.Fatalf("send %d: %v", i, err)
+				}
+			}
+			if tt.failAt >= 0 {
+				t.Errorf("send %d wasn't refused", tt.failAt)
+			}
+		})
+	}
+}
+
+func TestOtpSendUnverifiedPhone(t *testing.T) {
+	otpService, sender := newTestOtpService(t, config.OTPConfig{Channels: []string{OTPChannelSMS}})
+	channel, err := otpService.Channel(OTPChannelSMS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Phone: "+14155550100"}
+
+	if err := otpService.Send(context.Background(), user, channel, models.ClientInfo{}); !hasCode(err, apperrors.CodeInvalidInput) {
+		t.Errorf("Send() error = %v, want INVALID_INPUT", err)
+	}
+	// Verification codes go to the declared number
+	if err := otpService.SendPhoneVerification(context.Background(), user, models.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	messages := sender.Messages()
+	if len(messages) != 1 || messages[0].To != user.Phone {
+		t.Fatalf("sent %+v, want one text to %s", messages, user.Phone)
+	}
+
+	code := regexp.MustCompile(`\d{6}`).FindString(messages[0].Body)
+	if ok, err := otpService.Verify(context.Background(), user.ID, code); err != nil || ok {
+		t.Errorf("sign-in Verify() = %v, %v; want the phone code rejected", ok, err)
+	}
+	if ok, err := otpService.VerifyPhone(context.Background(), user, code); err != nil || !ok {
+		t.Errorf("VerifyPhone() = %v, %v; want true", ok, err)
+	}
+}
@@ -15,8 +15,8 @@ type RedisService struct {
 	ttl    time.Duration
 }
 
-// NewRedisService creates a new Redis service
-func NewRedisService(cfg config.RedisConfig) (*RedisService, error) {
+// NewRedisService creates a new Redis service, checking the connection within ctx
+func NewRedisService(ctx context.Context, cfg config.RedisConfig) (*RedisService, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
@@ -24,7 +24,7 @@ func NewRedisService(cfg config.RedisConfig) (*RedisService, error) {
 	})
 
 	// Test the connection
-	if err := rdb.Ping(context.Background()).Err(); err != nil {
+	if err := rdb.Ping(ctx).Err(); err != nil {
 		return nil, err
 	}
 
@@ -52,6 +52,36 @@ func (r *RedisService) Delete(ctx context.Context, keys ...string) error {
 	return r.client.Del(ctx, keys...).Err()
 }
 
+// Increment adds one to the counter at key and returns the new value. The
+// first increment starts a window that expires the counter after window.
+func (r *RedisService) Increment(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// Ping checks that Redis is reachable
+func (r *RedisService) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Client returns the underlying client for packages built on Redis, such as the job queue
+func (r *RedisService) Client() *redis.Client {
+	return r.client
+}
+
+// AddHook instruments every command with hook
+func (r *RedisService) AddHook(hook redis.Hook) {
+	r.client.AddHook(hook)
+}
+
 // Close closes the Redis connection
 func (r *RedisService) Close() error {
 	return r.client.Close()
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/scheduler"
+	"go.uber.org/zap"
+)
+
+// Scheduled job names, as recorded in the run history
+const (
+	ScheduledPurgeDeletedUsers  = "users.purge_deleted"
+	ScheduledPruneEmailOutbox   = "email_outbox.prune"
+	ScheduledPruneRuns          = "scheduled_runs.prune"
+	ScheduledRotateJWTKey       = "jwt.rotate_key"
+	ScheduledCleanupSigningKeys = "signing_keys.cleanup"
+)
+
+// RegisterScheduledJobs registers the maintenance jobs that have a schedule in cfg
+func RegisterScheduledJobs(sched *scheduler.Scheduler, cfg config.SchedulerConfig, userService *UserService, emailService *EmailService, jwtService *JWTService, history *scheduler.History, logger *zap.Logger) error {
+	jobs := []struct {
+		name string
+		spec string
+		fn   scheduler.Func
+	}{
+		{ScheduledPurgeDeletedUsers, cfg.PurgeDeletedUsers, func(ctx context.Context) error {
+			deleted, err := userService.PurgeDeletedUsers(ctx, cfg.DeletedUserRetention)
+			if err != nil {
+				return err
+			}
+			logger.Info("Purged deleted users", zap.Int64("deleted", deleted))
+			return nil
+		}},
+		{ScheduledPruneEmailOutbox, cfg.PruneEmailOutbox, func(ctx context.Context) error {
+			deleted, err := emailService.PruneOutbox(ctx, cfg.EmailOutboxRetention)
+			if err != nil {
+				return err
+			}
+			logger.Info("Pruned email outbox", zap.Int64("deleted", deleted))
+			return nil
+		}},
+		{ScheduledPruneRuns, cfg.PruneRuns, func(ctx context.Context) error {
+			deleted, err := history.Prune(ctx, cfg.RunRetention)
+			if err != nil {
+				return err
+			}
+			logger.Info("Pruned scheduled run history", zap.Int64("deleted", deleted))
+			return nil
+		}},
+		{ScheduledRotateJWTKey, cfg.RotateJWTKey, func(ctx context.Context) error {
+			key, err := jwtService.RotateKey(ctx, cfg.JWTKeyGrace)
+			if err != nil {
+				return err
+			}
+			logger.Info("Rotated JWT signing key", zap.String("kid", key.ID), zap.Duration("grace", cfg.JWTKeyGrace))
+			return nil
+		}},
+		{ScheduledCleanupSigningKeys, cfg.CleanupSigningKeys, func(ctx context.Context) error {
+			deleted, err := jwtService.DeleteRetiredKeys(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("Deleted retired signing keys", zap.Int64("deleted", deleted))
+			return nil
+		}},
+	}
+	for _, job := range jobs {
+		if job.spec == "" {
+			continue
+		}
+		if err := sched.Register(job.name, job.spec, cfg.Timeout, job.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,60 +1,173 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
 	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/utils"
 	"gorm.io/gorm"
 )
 
 // UserService handles user management operations
 type UserService struct {
-	db *gorm.DB
+	db  *gorm.DB
+	bus *events.Bus
 }
 
-// NewUserService creates a new user service
-func NewUserService(db *gorm.DB) *UserService {
+// NewUserService creates a new user service. Changes are published on bus
+// unless it is nil.
+func NewUserService(db *gorm.DB, bus *events.Bus) *UserService {
 	return &UserService{
-		db: db,
+		db:  db,
+		bus: bus,
 	}
 }
 
 // GetUserByID retrieves a user by ID
-func (u *UserService) GetUserByID(userID uuid.UUID) (*models.User, error) {
+func (u *UserService) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := u.db.Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+	if err := u.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+// CreateUser creates an active user with the given role
+func (u *UserService) CreateUser(ctx context.Context, input models.RegisterInput, role string) (*models.User, error) {
+	var existingUser models.User
+	err := u.db.WithContext(ctx).Where("email = ? OR username = ?", input.Email, input.Username).First(&existingUser).Error
+	if err == nil {
+		return nil, apperrors.ErrUserExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.Hash(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Email:     input.Email,
+		Username:  input.Username,
+		Password:  hashedPassword,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Phone:     input.Phone,
+		Role:      role,
+		Locale:    input.Locale,
+		IsActive:  true,
+	}
+	err = transaction(ctx, u.db, u.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return emit(events.UserRegistered{User: user.ToResponse()})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// ResetPassword sets a new password for the user with the given email or username
+func (u *UserService) ResetPassword(ctx context.Context, identifier, password string) error {
+	var user models.User
+	if err := u.db.WithContext(ctx).Where("email = ? OR username = ?", identifier, identifier).First(&user).Error; err != nil {
+		return notFound(err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	hashedPassword, err := utils.Hash(password)
+	if err != nil {
+		return err
+	}
+	return transaction(ctx, u.db, u.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return emit(events.PasswordChanged{User: user.ToResponse()})
+	})
+}
+
 // UpdateUser updates user information
-func (u *UserService) UpdateUser(userID uuid.UUID, firstName, lastName string) (*models.User, error) {
+func (u *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, firstName, lastName string) (*models.User, error) {
 	var user models.User
-	if err := u.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return nil, err
+	if err := u.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, notFound(err)
 	}
 
 	user.FirstName = firstName
 	user.LastName = lastName
 
-	if err := u.db.Save(&user).Error; err != nil {
+	err := transaction(ctx, u.db, u.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return emit(events.UserUpdated{User: user.ToResponse()})
+	})
+	if err != nil {
 		return nil, err
 	}
-
 	return &user, nil
 }
 
 // DeleteUser soft deletes a user
-func (u *UserService) DeleteUser(userID uuid.UUID) error {
-	return u.db.Where("id = ?", userID).Delete(&models.User{}).Error
+func (u *UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	return transaction(ctx, u.db, u.bus, func(tx *gorm.DB, emit func(events.Event) error) error {
+		result := tx.Where("id = ?", userID).Delete(&models.User{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return emit(events.UserDeleted{UserID: userID})
+	})
+}
+
+// PurgeDeletedUsers permanently deletes users soft deleted more than
+// olderThan ago and returns how many
+func (u *UserService) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := u.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-olderThan)).
+		Delete(&models.User{})
+	return result.RowsAffected, result.Error
 }
 
 // ListUsers retrieves all active users with pagination
-func (u *UserService) ListUsers(limit, offset int) ([]models.User, error) {
+func (u *UserService) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	err := u.db.Where("is_active = ?", true).
+	err := u.db.WithContext(ctx).Where("is_active = ?", true).
 		Limit(limit).
 		Offset(offset).
 		Find(&users).Error
 	return users, err
 }
+
+// transaction runs fn in a transaction whose events are published on bus, or
+// dropped when bus is nil
+func transaction(ctx context.Context, db *gorm.DB, bus *events.Bus, fn func(tx *gorm.DB, emit func(events.Event) error) error) error {
+	if bus != nil {
+		return bus.Transaction(ctx, fn)
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(tx, func(events.Event) error { return nil })
+	})
+}
+
+// notFound maps a missing record to ErrUserNotFound and passes other errors through
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.ErrUserNotFound
+	}
+	return err
+}
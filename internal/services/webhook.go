@@ -0,0 +1,516 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/events"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/jobs"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/metrics"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobDeliverWebhook sends one webhook delivery
+const JobDeliverWebhook = "webhook.deliver"
+
+// DeliverWebhookPayload identifies the delivery a webhook.deliver job sends
+type DeliverWebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// Headers of a webhook request
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// WebhookService manages webhook endpoints and delivers events to them.
+// Deliveries are sent by webhook.deliver jobs, so failures are retried
+// with the job queue's backoff.
+type WebhookService struct {
+	db        *gorm.DB
+	jobClient *jobs.Client
+	client    *http.Client
+	cfg       config.WebhooksConfig
+	userAgent string
+}
+
+// NewWebhookService creates a webhook service that queues deliveries with jobClient
+func NewWebhookService(db *gorm.DB, jobClient *jobs.Client, cfg config.WebhooksConfig, appName string) *WebhookService {
+	return &WebhookService{
+		db:        db,
+		jobClient: jobClient,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: webhookTransport(cfg.AllowPrivateNetworks),
+			// A redirect is a failed delivery; the endpoint URL should be updated instead
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:       cfg,
+		userAgent: appName + "-Webhooks/1.0",
+	}
+}
+
+// webhookTransport returns the transport of webhook requests. Unless private
+// networks are allowed, it refuses to connect to non-public addresses after
+// DNS resolution, so a hostname can't be pointed at internal services later.
+func webhookTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook endpoint resolves to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the endpoint, bypassing the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// publicAddr reports whether addr may receive webhooks: not loopback,
+// private, link-local, multicast or unspecified
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// checkEndpointURL rejects endpoint URLs that name a non-public address or
+// localhost, unless private networks are allowed. Hostnames are checked
+// again when each request connects.
+func (w *WebhookService) checkEndpointURL(rawURL string) error {
+	if w.cfg.AllowPrivateNetworks {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "Invalid webhook URL", err)
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return apperrors.New(apperrors.CodeInvalidInput, "Webhook URL must not point to a private network")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return apperrors.New(apperrors.CodeInvalidInput, "Webhook URL must not point to a private network")
+	}
+	return nil
+}
+
+// SignWebhook returns the signature header value of body sent at timestamp:
+// the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateEndpoint registers an endpoint with a new signing secret
+func (w *WebhookService) CreateEndpoint(ctx context.Context, input models.WebhookEndpointInput) (*models.WebhookEndpointSecret, error) {
+	if err := w.checkEndpointURL(input.URL); err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := models.WebhookEndpoint{
+		URL:         input.URL,
+		Description: input.Description,
+		Secret:      secret,
+		Events:      input.Events,
+		Active:      input.Active == nil || *input.Active,
+	}
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	if err := w.db.WithContext(ctx).Create(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &models.WebhookEndpointSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// ListEndpoints returns endpoints, newest first
+func (w *WebhookService) ListEndpoints(ctx context.Context, limit, offset int) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := w.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&endpoints).Error
+	return endpoints, err
+}
+
+// GetEndpoint returns one endpoint
+func (w *WebhookService) GetEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := w.db.WithContext(ctx).Where("id = ?", id).First(&endpoint).Error; err != nil {
+		return nil, webhookNotFound(err, "Webhook endpoint not found")
+	}
+	return &endpoint, nil
+}
+
+// UpdateEndpoint replaces an endpoint's URL, description, events and state.
+// The secret is kept.
+func (w *WebhookService) UpdateEndpoint(ctx context.Context, id uuid.UUID, input models.WebhookEndpointInput) (*models.WebhookEndpoint, error) {
+	if err := w.checkEndpointURL(input.URL); err != nil {
+		return nil, err
+	}
+	endpoint, err := w.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.URL = input.URL
+	endpoint.Description = input.Description
+	endpoint.Events = input.Events
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	endpoint.Active = input.Active == nil || *input.Active
+	if err := w.db.WithContext(ctx).Save(endpoint).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint deletes an endpoint along with its deliveries
+func (w *WebhookService) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	result := w.db.WithContext(ctx).Where("id = ?", id).Delete(&models.WebhookEndpoint{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.New(apperrors.CodeNotFound, "Webhook endpoint not found")
+	}
+	return nil
+}
+
+// Subscribe announces user events from the bus to webhook endpoints. The
+// handlers are synchronous, so an event leaves the outbox only once its
+// deliveries are recorded.
+func (w *WebhookService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.NameUserRegistered, func(ctx context.Context, event events.Event) error {
+		return w.Dispatch(ctx, models.WebhookEventUserCreated, event.(events.UserRegistered).User)
+	})
+	bus.Subscribe(events.NameUserUpdated, func(ctx context.Context, event events.Event) error {
+		return w.Dispatch(ctx, models.WebhookEventUserUpdated, event.(events.UserUpdated).User)
+	})
+	bus.Subscribe(events.NameUserDeleted, func(ctx context.Context, event events.Event) error {
+		return w.Dispatch(ctx, models.WebhookEventUserDeleted, map[string]uuid.UUID{"id": event.(events.UserDeleted).UserID})
+	})
+	bus.Subscribe(events.NameUserLoggedIn, func(ctx context.Context, event events.Event) error {
+		return w.Dispatch(ctx, models.WebhookEventUserLogin, event.(events.UserLoggedIn).User)
+	})
+	bus.Subscribe(events.NamePasswordChanged, func(ctx context.Context, event events.Event) error {
+		return w.Dispatch(ctx, models.WebhookEventPasswordChanged, event.(events.PasswordChanged).User)
+	})
+}
+
+// Dispatch queues a delivery of event to every active endpoint subscribed to
+// it. A failure for one endpoint doesn't stop the others. The delivery takes
+// the ID of the bus event being handled, so receivers can deduplicate, and is
+// only queued once per endpoint when the bus event is redelivered.
+func (w *WebhookService) Dispatch(ctx context.Context, event string, data interface{}) error {
+	var endpoints []models.WebhookEndpoint
+	if err := w.db.WithContext(ctx).Where("active = ?", true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+	eventID := events.IDFromContext(ctx)
+	if eventID == uuid.Nil {
+		eventID = uuid.New()
+	}
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        eventID,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribed(event) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			EndpointID: endpoint.ID,
+			EventID:    eventID,
+			Event:      event,
+			Payload:    payload,
+		}
+		result := w.db.WithContext(ctx).
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}}, DoNothing: true}).
+			Create(&delivery)
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("endpoint %s: %w", endpoint.ID, result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			// Queued when the event was delivered before
+			continue
+		}
+		if err := w.enqueue(ctx, delivery.ID); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.ID, err))
+			// Leave it replayable
+			delivery.LastError = "failed to queue: " + err.Error()
+			if err := w.markFailed(ctx, &delivery); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendTest sends a webhook.test event to an endpoint right away, whatever
+// its filter and state, and returns the delivery with its attempt
+func (w *WebhookService) SendTest(ctx context.Context, endpointID uuid.UUID) (*models.WebhookDelivery, error) {
+	endpoint, err := w.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	eventID := uuid.New()
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        eventID,
+		Type:      models.WebhookEventTest,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]string{"endpoint_id": endpoint.ID.String()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := models.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		EventID:    eventID,
+		Event:      models.WebhookEventTest,
+		Payload:    payload,
+	}
+	if err := w.db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	// Test deliveries aren't retried, but a failed one can be replayed
+	if err := w.attempt(ctx, &delivery, endpoint); err != nil {
+		if err := w.markFailed(ctx, &delivery); err != nil {
+			return nil, err
+		}
+	}
+	return w.GetDelivery(ctx, delivery.ID)
+}
+
+// ListDeliveries returns an endpoint's deliveries, newest first, optionally filtered by status
+func (w *WebhookService) ListDeliveries(ctx context.Context, endpointID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	if _, err := w.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+	query := w.db.WithContext(ctx).Where("endpoint_id = ?", endpointID).Order("created_at DESC").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []models.WebhookDelivery
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetDelivery returns a delivery with its attempts
+func (w *WebhookService) GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := w.db.WithContext(ctx).
+		Preload("AttemptLog", func(tx *gorm.DB) *gorm.DB { return tx.Order("attempt") }).
+		Where("id = ?", id).
+		First(&delivery).Error
+	if err != nil {
+		return nil, webhookNotFound(err, "Webhook delivery not found")
+	}
+	return &delivery, nil
+}
+
+// ReplayDelivery queues a failed delivery again with a fresh set of attempts
+func (w *WebhookService) ReplayDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&delivery).Error; err != nil {
+			return webhookNotFound(err, "Webhook delivery not found")
+		}
+		if delivery.Status != models.DeliveryStatusFailed {
+			return apperrors.New(apperrors.CodeConflict, "Only failed deliveries can be replayed")
+		}
+		return tx.Model(&delivery).Update("status", models.DeliveryStatusPending).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.enqueue(ctx, delivery.ID); err != nil {
+		if markErr := w.markFailed(ctx, &delivery); markErr != nil {
+			return nil, markErr
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// HandleDeliveryJob runs a webhook.deliver job. Failed requests are returned
+// so the job is retried; once the job is out of attempts the delivery is
+// marked failed.
+func (w *WebhookService) HandleDeliveryJob(ctx context.Context, job *jobs.Job) error {
+	var payload DeliverWebhookPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	var delivery models.WebhookDelivery
+	if err := w.db.WithContext(ctx).Where("id = ?", payload.DeliveryID).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Deleted along with its endpoint
+			return nil
+		}
+		return err
+	}
+	if delivery.Status != models.DeliveryStatusPending {
+		return nil
+	}
+	var endpoint models.WebhookEndpoint
+	if err := w.db.WithContext(ctx).Where("id = ?", delivery.EndpointID).First(&endpoint).Error; err != nil {
+		return err
+	}
+	if !endpoint.Active {
+		delivery.LastError = "endpoint is disabled"
+		return w.markFailed(ctx, &delivery)
+	}
+
+	err := w.attempt(ctx, &delivery, &endpoint)
+	if err != nil && job.Attempts >= job.MaxAttempts {
+		if markErr := w.markFailed(ctx, &delivery); markErr != nil {
+			return markErr
+		}
+	}
+	return err
+}
+
+// attempt sends delivery to endpoint once and records the outcome
+func (w *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery, endpoint *models.WebhookEndpoint) error {
+	start := time.Now()
+	statusCode, sendErr := w.send(ctx, delivery, endpoint)
+	record := models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts + 1,
+		StatusCode: statusCode,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	updates := map[string]interface{}{
+		"attempts":        record.Attempt,
+		"response_status": statusCode,
+	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+		updates["last_error"] = record.Error
+		metrics.WebhookAttemptsTotal.WithLabelValues(delivery.Event, "failure").Inc()
+	} else {
+		now := time.Now()
+		updates["status"] = models.DeliveryStatusSucceeded
+		updates["last_error"] = ""
+		updates["delivered_at"] = now
+		metrics.WebhookAttemptsTotal.WithLabelValues(delivery.Event, "success").Inc()
+	}
+
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		return tx.Model(delivery).Updates(updates).Error
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send posts the delivery's payload to the endpoint and returns the response status
+func (w *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery, endpoint *models.WebhookEndpoint) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", w.userAgent)
+	req.Header.Set(WebhookHeaderEvent, delivery.Event)
+	req.Header.Set(WebhookHeaderDelivery, delivery.ID.String())
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhook(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a bounded amount so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// enqueue queues a webhook.deliver job for the delivery
+func (w *WebhookService) enqueue(ctx context.Context, deliveryID uuid.UUID) error {
+	_, err := w.jobClient.Enqueue(ctx, JobDeliverWebhook, DeliverWebhookPayload{DeliveryID: deliveryID}, jobs.Options{
+		MaxAttempts: w.cfg.MaxAttempts,
+		UniqueKey:   deliveryID.String(),
+	})
+	return err
+}
+
+// markFailed marks a delivery failed, so it can be replayed
+func (w *WebhookService) markFailed(ctx context.Context, delivery *models.WebhookDelivery) error {
+	updates := map[string]interface{}{"status": models.DeliveryStatusFailed}
+	if delivery.LastError != "" {
+		updates["last_error"] = delivery.LastError
+	}
+	return w.db.WithContext(ctx).Model(delivery).Updates(updates).Error
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// webhookNotFound maps a missing row to a NOT_FOUND error with message
+func webhookNotFound(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, message, err)
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"type":"user.created"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      string
+	}{
+		{
+			name:      "known signature",
+			secret:    "whsec_test",
+			timestamp: 1700000000,
+			body:      body,
+			want:      "sha256=2309b3241c934edd598182cd8af8663e23a4ed93bae9e076fbd3e8df8202253b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignWebhook(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("SignWebhook() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// The timestamp and the secret are both covered by the signature
+	signed := SignWebhook("whsec_test", 1700000000, body)
+	if SignWebhook("whsec_test", 1700000001, body) == signed {
+		t.Error("signature doesn't depend on the timestamp")
+	}
+	if SignWebhook("whsec_other", 1700000000, body) == signed {
+		t.Error("signature doesn't depend on the secret")
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"::ffff:93.184.216.34", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("publicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEndpointURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{url: "https://hooks.example.com/in"},
+		{url: "https://93.184.216.34/in"},
+		{url: "http://localhost:8080/in", wantErr: true},
+		{url: "http://api.localhost./in", wantErr: true},
+		{url: "http://127.0.0.1/in", wantErr: true},
+		{url: "http://[::1]/in", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://127.0.0.1/in", allowPrivate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			w := &WebhookService{cfg: config.WebhooksConfig{AllowPrivateNetworks: tt.allowPrivate}}
+			err := w.checkEndpointURL(tt.url)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkEndpointURL(%s) error = %v, want error %v", tt.url, err, tt.wantErr)
+			}
+			if err != nil && !hasCode(err, apperrors.CodeInvalidInput) {
+				t.Errorf("checkEndpointURL(%s) error = %v, want INVALID_INPUT", tt.url, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Message is a text message recorded by Fake
+type Message struct {
+	To   string
+	Body string
+}
+
+// Fake logs messages instead of sending them and keeps them for tests to
+// inspect
+type Fake struct {
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewFake creates a fake sender logging to logger
+func NewFake(logger *zap.Logger) *Fake {
+	return &Fake{logger: logger}
+}
+
+// Send logs and records the message
+func (f *Fake) Send(ctx context.Context, to, body string) error {
+	f.logger.Info("SMS", zap.String("to", to), zap.String("body", body))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, Message{To: to, Body: body})
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
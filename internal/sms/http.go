@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+)
+
+// HTTPGateway posts messages as JSON to a gateway URL:
+//
+//	{"from": "...", "to": "+14155550123", "message": "..."}
+//
+// with the API key as a bearer token. Any 2xx response is a success.
+type HTTPGateway struct {
+	url    string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+// NewHTTPGateway creates a gateway sender
+func NewHTTPGateway(cfg config.SMSConfig) *HTTPGateway {
+	return &HTTPGateway{
+		url:    cfg.GatewayURL,
+		apiKey: cfg.APIKey,
+		from:   cfg.From,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+type gatewayRequest struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// Send posts the message to the gateway
+func (g *HTTPGateway) Send(ctx context.Context, to, body string) error {
+	payload, err := json.Marshal(gatewayRequest{From: g.from, To: to, Message: body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms: gateway returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package sms sends text messages through an HTTP gateway, or a fake that
+// logs them for development and tests.
+package sms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"go.uber.org/zap"
+)
+
+// Provider names accepted by SMS_PROVIDER
+const (
+	ProviderHTTP = "http"
+	ProviderFake = "fake"
+)
+
+// Sender sends a text message to an E.164 phone number
+type Sender interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// New creates the sender for the configured provider
+func New(cfg config.SMSConfig, logger *zap.Logger) (Sender, error) {
+	switch cfg.Provider {
+	case ProviderHTTP:
+		return NewHTTPGateway(cfg), nil
+	case ProviderFake:
+		return NewFake(logger), nil
+	}
+	return nil, fmt.Errorf("sms: unknown provider %q", cfg.Provider)
+}
@@ -0,0 +1,74 @@
+// Package tracing configures OpenTelemetry and provides the span
+// instrumentation for HTTP, GORM, Redis and SMTP.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/md-asharaf/go-fiber-boilerplate"
+
+// Tracer returns the application tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// NewExporter creates the OTLP/HTTP exporter. The endpoint and headers come
+// from the standard OTEL_EXPORTER_OTLP_* environment variables.
+func NewExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx)
+}
+
+// InstallPropagator makes the global propagator honor W3C traceparent and
+// baggage headers. It is useful even with tracing disabled, since the incoming
+// trace ID still reaches the logs.
+func InstallPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Setup installs a global tracer provider that batches spans to exporter and
+// returns a function that flushes and stops it. Tests can pass an in-memory
+// exporter such as tracetest.NewInMemoryExporter.
+func Setup(cfg config.TracingConfig, exporter sdktrace.SpanExporter) (func(ctx context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// LogFields returns the trace and span IDs in ctx as zap fields
+func LogFields(ctx context.Context) []zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	}
+}
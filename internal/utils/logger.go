@@ -1,12 +1,17 @@
 package utils
 
 import (
+	"context"
 	"os"
 
+	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
-// InitLogger initializes the logger based on the environment
+type loggerContextKey struct{}
+
+// InitLogger initializes the logger based on the environment and installs it
+// as the global logger, which LoggerFromContext falls back to
 func InitLogger() *zap.Logger {
 	var zapConfig zap.Config
 
@@ -32,9 +37,39 @@ func InitLogger() *zap.Logger {
 		zap.AddCaller(),
 		zap.AddCallerSkip(1),
 		zap.AddStacktrace(zap.ErrorLevel),
+		zap.WrapCore(NewRedactingCore),
 	)
 	if err != nil {
 		return zap.L()
 	}
+	zap.ReplaceGlobals(logger)
 	return logger
 }
+
+// ContextWithLogger returns a copy of ctx carrying logger
+func ContextWithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the request-scoped logger in ctx, or the global logger
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	return LoggerFromContextOr(ctx, zap.L())
+}
+
+// LoggerFromContextOr returns the request-scoped logger in ctx, or fallback
+// for work that runs outside a request
+func LoggerFromContextOr(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// RequestLogger returns the request-scoped logger stored in c.Locals("logger"),
+// which carries the request and trace IDs
+func RequestLogger(c *fiber.Ctx) *zap.Logger {
+	if logger, ok := c.Locals("logger").(*zap.Logger); ok {
+		return logger
+	}
+	return LoggerFromContext(c.UserContext())
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue replaces sensitive values in logs
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeyParts marks a log field, header or JSON key as sensitive when
+// its lowercased name contains any of them
+var sensitiveKeyParts = []string{"password", "token", "secret", "authorization", "cookie", "otp"}
+
+// sensitiveKeys are sensitive only as whole names: email bodies carry one-time
+// codes and links, but "text" is part of too many harmless names
+var sensitiveKeys = []string{"text", "html"}
+
+// IsSensitiveKey reports whether values stored under key must not be logged
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if slices.Contains(sensitiveKeys, key) {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns a copy of headers with sensitive values replaced
+func RedactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if IsSensitiveKey(key) {
+			value = RedactedValue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// RedactJSON returns body with the values of sensitive keys replaced at any
+// depth. Bodies that aren't valid JSON are dropped entirely.
+func RedactJSON(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return RedactedValue
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return RedactedValue
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if IsSensitiveKey(key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactValue(inner)
+			}
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+	}
+	return value
+}
+
+// redactingCore replaces the values of sensitive fields before they are encoded
+type redactingCore struct {
+	zapcore.Core
+}
+
+// NewRedactingCore wraps core so fields with sensitive keys are never written
+func NewRedactingCore(core zapcore.Core) zapcore.Core {
+	return redactingCore{Core: core}
+}
+
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := fields
+	copied := false
+	for i, field := range fields {
+		if !IsSensitiveKey(field.Key) {
+			continue
+		}
+		if !copied {
+			// Copy before the first change so the caller's slice is untouched
+			redacted = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		redacted[i] = zap.String(field.Key, RedactedValue)
+	}
+	return redacted
+}
@@ -1,16 +1,37 @@
 package utils
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
 )
 
 // ErrorResponse represents a standard error response for Fiber
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Status  int    `json:"status"`
+	Error     string      `json:"error"`
+	Code      string      `json:"code,omitempty"`
+	Message   string      `json:"message"`
+	Status    int         `json:"status"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// MIMEApplicationProblemJSON is the RFC 9457 problem details media type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemDetails represents an RFC 9457 problem details response for Fiber
+type ProblemDetails struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // SuccessResponse represents a standard success response for Fiber
@@ -29,6 +50,49 @@ func WriteErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
 	})
 }
 
+// WriteErrorResponseWithDetails writes a standard error response with extra details in Fiber
+func WriteErrorResponseWithDetails(c *fiber.Ctx, statusCode int, message string, details interface{}) error {
+	return c.Status(statusCode).JSON(ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Status:  statusCode,
+		Details: details,
+	})
+}
+
+// WriteAppErrorResponse writes a domain error in the standard error format in Fiber
+func WriteAppErrorResponse(c *fiber.Ctx, err *apperrors.Error, requestID string) error {
+	statusCode := err.Status()
+	return c.Status(statusCode).JSON(ErrorResponse{
+		Error:     http.StatusText(statusCode),
+		Code:      string(err.Code),
+		Message:   err.Message,
+		Status:    statusCode,
+		Details:   err.Details,
+		RequestID: requestID,
+	})
+}
+
+// WriteProblemResponse writes a domain error as application/problem+json in Fiber
+func WriteProblemResponse(c *fiber.Ctx, err *apperrors.Error, requestID string) error {
+	statusCode := err.Status()
+	body, marshalErr := json.Marshal(ProblemDetails{
+		Type:      "urn:problem-type:" + strings.ToLower(strings.ReplaceAll(string(err.Code), "_", "-")),
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    err.Message,
+		Instance:  c.OriginalURL(),
+		Code:      string(err.Code),
+		Errors:    err.Details,
+		RequestID: requestID,
+	})
+	if marshalErr != nil {
+		return marshalErr
+	}
+	c.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+	return c.Status(statusCode).Send(body)
+}
+
 // WriteSuccessResponse writes a standard success response in Fiber
 func WriteSuccessResponse(c *fiber.Ctx, data interface{}, message string) error {
 	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
@@ -1,18 +1,96 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/apperrors"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+// FieldError describes one failed validation rule on a request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
 
+// newValidator returns a validator that reports fields by their JSON names,
+// with a `locale` rule and translated messages for every supported locale
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	_ = v.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return i18n.IsSupported(fl.Field().String())
+	})
+	if err := i18n.RegisterValidatorTranslations(v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// ParseAndValidateInput parses the request body into i and validates it. The
+// returned error is an *apperrors.Error for the app's error handler to render;
+// validation failures carry a []FieldError as details.
 func ParseAndValidateInput(c *fiber.Ctx, i interface{}) error {
 	if err := c.BodyParser(i); err != nil {
-		return WriteErrorResponse(c, fiber.StatusBadRequest, "Invalid input")
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "Invalid input", err)
 	}
-	if err := validate.Struct(i); err != nil {
-		return WriteErrorResponse(c, fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	if err := ValidateStruct(i); err != nil {
+		return ValidationError(c.UserContext(), err)
 	}
 	return nil
 }
+
+// ValidateStruct validates i against its `validate` struct tags
+func ValidateStruct(i interface{}) error {
+	return validate.Struct(i)
+}
+
+// ValidationError converts a validator error into a VALIDATION_FAILED error
+// with one FieldError per failed rule, localized for ctx
+func ValidationError(ctx context.Context, err error) *apperrors.Error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "Invalid input", err)
+	}
+	return apperrors.Wrap(apperrors.CodeValidationFailed, "Validation failed", err).
+		WithDetails(FieldErrors(ctx, validationErrors))
+}
+
+// FieldErrors converts validator errors into client-facing field errors in
+// the locale carried by ctx
+func FieldErrors(ctx context.Context, validationErrors validator.ValidationErrors) []FieldError {
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: i18n.FieldErrorMessage(ctx, fe),
+		})
+	}
+	return fieldErrors
+}
+
+// fieldPath returns the JSON path of the field without the root struct name
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
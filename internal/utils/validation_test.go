@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/md-asharaf/go-fiber-boilerplate/internal/i18n"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type testInput struct {
+	Username string        `json:"username" validate:"required,min=3"`
+	Role     string        `json:"role" validate:"omitempty,oneof=user admin"`
+	Address  testAddress   `json:"address"`
+	Tags     []testAddress `json:"tags" validate:"dive"`
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		input  testInput
+		want   []FieldError
+	}{
+		{
+			name:   "rule and param",
+			locale: "en",
+			input:  testInput{Username: "ab", Role: "root", Address: testAddress{City: "Paris"}},
+			want: []FieldError{
+				{Field: "username", Rule: "min", Param: "3", Message: "username must be at least 3 characters long"},
+				{Field: "role", Rule: "oneof", Param: "user admin", Message: "role must be one of: user admin"},
+			},
+		},
+		{
+			name:   "nested fields",
+			locale: "en",
+			input:  testInput{Username: "alice", Tags: []testAddress{{City: "Rome"}, {}}},
+			want: []FieldError{
+				{Field: "address.city", Rule: "required", Message: "city is required"},
+				{Field: "tags[1].city", Rule: "required", Message: "city is required"},
+			},
+		},
+		{
+			name:   "localized",
+			locale: "es",
+			input:  testInput{Address: testAddress{City: "Lima"}},
+			want: []FieldError{
+				{Field: "username", Rule: "required", Message: "username es obligatorio"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := i18n.WithLocale(context.Background(), tt.locale)
+			appErr := ValidationError(ctx, ValidateStruct(tt.input))
+			got, ok := appErr.Details.([]FieldError)
+			if !ok {
+				t.Fatalf("details are %T, want []FieldError", appErr.Details)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldErrors() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteProblemResponseFieldErrors(t *testing.T) {
+	app := fiber.New()
+	app.Post("/users", func(c *fiber.Ctx) error {
+		err := ValidateStruct(testInput{Username: "ab", Address: testAddress{City: "Oslo"}})
+		return WriteProblemResponse(c, ValidationError(c.UserContext(), err), "req-1")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/users?draft=1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if got := resp.Header.Get(fiber.HeaderContentType); got != MIMEApplicationProblemJSON {
+		t.Errorf("content type = %q, want %q", got, MIMEApplicationProblemJSON)
+	}
+
+	var body struct {
+		ProblemDetails
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	want := ProblemDetails{
+		Type:      "urn:problem-type:validation-failed",
+		Title:     "Bad Request",
+		Status:    fiber.StatusBadRequest,
+		Detail:    "Validation failed",
+		Instance:  "/users?draft=1",
+		Code:      "VALIDATION_FAILED",
+		RequestID: "req-1",
+	}
+	body.ProblemDetails.Errors = nil
+	if body.ProblemDetails != want {
+		t.Errorf("problem = %+v, want %+v", body.ProblemDetails, want)
+	}
+	wantErrors := []FieldError{{Field: "username", Rule: "min", Param: "3", Message: "username must be at least 3 characters long"}}
+	if !reflect.DeepEqual(body.Errors, wantErrors) {
+		t.Errorf("errors = %+v, want %+v", body.Errors, wantErrors)
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id         TEXT PRIMARY KEY,
+    secret     TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    retires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_retires_at ON signing_keys (retires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS scheduled_runs (
+    id          UUID PRIMARY KEY,
+    job         TEXT NOT NULL,
+    instance    TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    error       TEXT NOT NULL DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_runs_job ON scheduled_runs (job, started_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_runs_started_at ON scheduled_runs (started_at);
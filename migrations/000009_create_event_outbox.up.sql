@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id           UUID PRIMARY KEY,
+    name         TEXT NOT NULL,
+    payload      JSONB NOT NULL,
+    locked_until TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_created_at ON event_outbox (created_at);
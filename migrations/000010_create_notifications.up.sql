@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category   TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    payload    JSONB NOT NULL DEFAULT '{}',
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Inbox pages are read newest first with a (created_at, id) cursor
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category   TEXT NOT NULL,
+    inbox      BOOLEAN NOT NULL,
+    email      BOOLEAN NOT NULL,
+    push       BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, category)
+);
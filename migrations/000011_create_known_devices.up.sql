@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS known_devices (
+    id            UUID PRIMARY KEY,
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_agent    TEXT NOT NULL,
+    ip            TEXT NOT NULL,
+    location      TEXT NOT NULL DEFAULT '',
+    latitude      DOUBLE PRECISION,
+    longitude     DOUBLE PRECISION,
+    first_seen_at TIMESTAMPTZ NOT NULL,
+    last_seen_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_known_devices_user_device ON known_devices (user_id, user_agent, ip);
+CREATE INDEX IF NOT EXISTS idx_known_devices_user_last_seen ON known_devices (user_id, last_seen_at DESC);
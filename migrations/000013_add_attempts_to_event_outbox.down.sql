@@ -0,0 +1,2 @@
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS last_error;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS attempts;
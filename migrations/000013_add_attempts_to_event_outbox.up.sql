@@ -0,0 +1,2 @@
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '';
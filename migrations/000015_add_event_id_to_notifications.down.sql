@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notifications_user_event;
+ALTER TABLE notifications DROP COLUMN IF EXISTS event_id;
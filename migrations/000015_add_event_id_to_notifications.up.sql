@@ -0,0 +1,4 @@
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS event_id UUID;
+
+-- A bus event redelivered to the notification service adds no second entry
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_event ON notifications (user_id, event_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS jwt_settings (
+    id                 BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    secret_replaced_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO jwt_settings (id, secret_replaced_at)
+SELECT TRUE, MIN(created_at) FROM signing_keys HAVING MIN(created_at) IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migration files so they ship
+// inside the binary.
+package migrations
+
+import "embed"
+
+// FS holds every *.up.sql and *.down.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS